@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/yunarta/terraform-api-transport/transport"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ClientExtension covers Bitbucket REST endpoints that are not available in
+// terraform-atlassian-api-client yet. It shares the transport, and therefore the
+// authentication, with the regular client.
+type ClientExtension struct {
+	transport transport.PayloadTransport
+
+	// properties caches the application properties, resources share the
+	// extension and use it concurrently
+	propertiesLock sync.Mutex
+	properties     *ApplicationProperties
+}
+
+func NewClientExtension(transport transport.PayloadTransport) *ClientExtension {
+	return &ClientExtension{
+		transport: transport,
+	}
+}
+
+// ExtendedReceiver is implemented by resources that need the ClientExtension in
+// addition to the regular client.
+type ExtendedReceiver interface {
+	setExtension(extension *ClientExtension)
+}
+
+type pagedReply[T any] struct {
+	Values        []T  `json:"values"`
+	IsLastPage    bool `json:"isLastPage"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+// getAllPages follows the Bitbucket paging parameters and returns the values of
+// every page.
+func getAllPages[T any](payloadTransport transport.PayloadTransport, endPoint string) ([]T, error) {
+	separator := "?"
+	if strings.Contains(endPoint, "?") {
+		separator = "&"
+	}
+
+	values := make([]T, 0)
+	start := 0
+	for {
+		reply, err := payloadTransport.SendWithExpectedStatus(&transport.PayloadRequest{
+			Method: http.MethodGet,
+			Url:    fmt.Sprintf("%s%sstart=%d", endPoint, separator, start),
+		}, 200)
+		if err != nil {
+			return nil, err
+		}
+
+		page := pagedReply[T]{}
+		err = reply.Object(&page)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, page.Values...)
+		if page.IsLastPage || len(page.Values) == 0 {
+			return values, nil
+		}
+		start = page.NextPageStart
+	}
+}
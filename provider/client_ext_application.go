@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/yunarta/terraform-api-transport/transport"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type ApplicationProperties struct {
+	Version     string `json:"version"`
+	BuildNumber string `json:"buildNumber"`
+	DisplayName string `json:"displayName"`
+}
+
+// MajorVersion returns the major component of the server version, such as 8 for
+// 8.19.1.
+func (properties ApplicationProperties) MajorVersion() (int, error) {
+	major, _, _ := strings.Cut(properties.Version, ".")
+	version, err := strconv.Atoi(major)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected server version %q", properties.Version)
+	}
+
+	return version, nil
+}
+
+// GetApplicationProperties returns the server version information. The result is
+// cached, as the server does not change during a run.
+func (extension *ClientExtension) GetApplicationProperties() (*ApplicationProperties, error) {
+	extension.propertiesLock.Lock()
+	defer extension.propertiesLock.Unlock()
+
+	if extension.properties != nil {
+		return extension.properties, nil
+	}
+
+	reply, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodGet,
+		Url:    "/rest/api/latest/application-properties",
+	}, 200)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := ApplicationProperties{}
+	err = reply.Object(&properties)
+	if err != nil {
+		return nil, err
+	}
+
+	extension.properties = &properties
+	return extension.properties, nil
+}
+
+// SupportsArchive tells whether the server has the repository archive API, which
+// was added in Bitbucket 8.0.
+func (extension *ClientExtension) SupportsArchive() (bool, error) {
+	properties, err := extension.GetApplicationProperties()
+	if err != nil {
+		return false, err
+	}
+
+	major, err := properties.MajorVersion()
+	if err != nil {
+		return false, err
+	}
+
+	return major >= 8, nil
+}
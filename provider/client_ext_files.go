@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/yunarta/terraform-api-transport/transport"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+type Commit struct {
+	Id        string `json:"id"`
+	DisplayId string `json:"displayId,omitempty"`
+}
+
+type EditFile struct {
+	Branch         string
+	Path           string
+	Content        string
+	Message        string
+	SourceCommitId string
+}
+
+// escapeFilePath escapes every segment of a file path for use in a URL path.
+func escapeFilePath(filePath string) string {
+	segments := strings.Split(filePath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// EditFile creates or updates a single file with a commit on the given branch.
+// SourceCommitId must be the current head of the branch when the file exists.
+func (extension *ClientExtension) EditFile(project, repo string, file EditFile) (*Commit, error) {
+	form := map[string]string{
+		"message": file.Message,
+	}
+	if file.Branch != "" {
+		form["branch"] = file.Branch
+	}
+	if file.SourceCommitId != "" {
+		form["sourceCommitId"] = file.SourceCommitId
+	}
+
+	reply, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodPut,
+		Url:    repositoryEndPoint(project, repo) + "/browse/" + escapeFilePath(file.Path),
+		Payload: &transport.MultipartPayload{
+			Form: form,
+			File: &transport.MultipartFile{
+				Key:     "content",
+				Name:    path.Base(file.Path),
+				Content: file.Content,
+			},
+		},
+	}, 200, 201)
+	if err != nil {
+		return nil, err
+	}
+
+	response := Commit{}
+	err = reply.Object(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// GetRawFile returns the content of a file at the given branch, and false when
+// the file does not exist.
+func (extension *ClientExtension) GetRawFile(project, repo, branch, filePath string) (string, bool, error) {
+	reply, err := extension.transport.Send(&transport.PayloadRequest{
+		Method: http.MethodGet,
+		Url: fmt.Sprintf("%s/raw/%s?at=%s", repositoryEndPoint(project, repo), escapeFilePath(filePath),
+			url.QueryEscape(branchRefId(branch))),
+	})
+	if err != nil {
+		return "", false, err
+	}
+
+	switch reply.StatusCode {
+	case http.StatusOK:
+		return reply.Body, true, nil
+	case http.StatusNotFound:
+		return "", false, nil
+	default:
+		return "", false, fmt.Errorf("unexpected status %d: %s", reply.StatusCode, reply.Body)
+	}
+}
+
+// GetBranchHead returns the latest commit of a branch, or nil when the branch
+// does not exist yet.
+func (extension *ClientExtension) GetBranchHead(project, repo, branch string) (*Commit, error) {
+	return extension.getLatestCommit(project, repo, branch, "")
+}
+
+// GetFileCommit returns the latest commit of a branch that changed the file, or
+// nil when there is none.
+func (extension *ClientExtension) GetFileCommit(project, repo, branch, filePath string) (*Commit, error) {
+	return extension.getLatestCommit(project, repo, branch, filePath)
+}
+
+func (extension *ClientExtension) getLatestCommit(project, repo, branch, filePath string) (*Commit, error) {
+	endPoint := fmt.Sprintf("%s/commits?until=%s&limit=1", repositoryEndPoint(project, repo),
+		url.QueryEscape(branchRefId(branch)))
+	if filePath != "" {
+		endPoint += "&path=" + url.QueryEscape(filePath)
+	}
+
+	reply, err := extension.transport.Send(&transport.PayloadRequest{
+		Method: http.MethodGet,
+		Url:    endPoint,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch reply.StatusCode {
+	case http.StatusOK:
+		response := pagedReply[Commit]{}
+		err = reply.Object(&response)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(response.Values) == 0 {
+			return nil, nil
+		}
+
+		return &response.Values[0], nil
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unexpected status %d: %s", reply.StatusCode, reply.Body)
+	}
+}
@@ -0,0 +1,121 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/yunarta/terraform-api-transport/transport"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type HookScope struct {
+	Type       string `json:"type,omitempty"`
+	ResourceId int64  `json:"resourceId,omitempty"`
+}
+
+type HookDetails struct {
+	Key  string `json:"key,omitempty"`
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
+}
+
+type Hook struct {
+	Details    HookDetails `json:"details"`
+	Enabled    bool        `json:"enabled"`
+	Configured bool        `json:"configured"`
+	Scope      HookScope   `json:"scope"`
+}
+
+// hookEndPoint returns the hook settings end point for a project, or for a
+// repository when repo is not empty.
+func hookEndPoint(project, repo, key string) string {
+	if repo == "" {
+		return fmt.Sprintf("/rest/api/latest/projects/%s/settings/hooks/%s",
+			url.PathEscape(project), url.PathEscape(key))
+	}
+
+	return fmt.Sprintf("/rest/api/latest/projects/%s/repos/%s/settings/hooks/%s",
+		url.PathEscape(project), url.PathEscape(repo), url.PathEscape(key))
+}
+
+func (extension *ClientExtension) GetHook(project, repo, key string) (*Hook, error) {
+	reply, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodGet,
+		Url:    hookEndPoint(project, repo, key),
+	}, 200)
+	if err != nil {
+		return nil, err
+	}
+
+	response := Hook{}
+	err = reply.Object(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// EnableHook enables the hook, and replaces its settings when settings is not empty.
+func (extension *ClientExtension) EnableHook(project, repo, key string, settings string) error {
+	request := &transport.PayloadRequest{
+		Method: http.MethodPut,
+		Url:    hookEndPoint(project, repo, key) + "/enabled",
+	}
+	if settings != "" {
+		request.Payload = &transport.JsonPayloadData{
+			Payload: json.RawMessage(settings),
+		}
+	}
+
+	_, err := extension.transport.SendWithExpectedStatus(request, 200)
+	return err
+}
+
+func (extension *ClientExtension) DisableHook(project, repo, key string) error {
+	_, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodDelete,
+		Url:    hookEndPoint(project, repo, key) + "/enabled",
+	}, 200)
+	return err
+}
+
+// GetHookSettings returns the raw settings JSON of the hook, or an empty object
+// when the hook has never been configured.
+func (extension *ClientExtension) GetHookSettings(project, repo, key string) (string, error) {
+	reply, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodGet,
+		Url:    hookEndPoint(project, repo, key) + "/settings",
+	}, 200, 204)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(reply.Body) == "" {
+		return "{}", nil
+	}
+
+	return reply.Body, nil
+}
+
+func (extension *ClientExtension) UpdateHookSettings(project, repo, key string, settings string) error {
+	_, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodPut,
+		Url:    hookEndPoint(project, repo, key) + "/settings",
+		Payload: &transport.JsonPayloadData{
+			Payload: json.RawMessage(settings),
+		},
+	}, 200, 204)
+	return err
+}
+
+// InheritHook removes the repository level hook configuration, so the
+// repository falls back to the project setting of the hook.
+func (extension *ClientExtension) InheritHook(project, repo, key string) error {
+	_, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodDelete,
+		Url:    hookEndPoint(project, repo, key),
+	}, 200, 204)
+	return err
+}
@@ -0,0 +1,341 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"github.com/yunarta/terraform-api-transport/transport"
+	"github.com/yunarta/terraform-atlassian-api-client/bitbucket"
+	"net/http"
+	"net/url"
+)
+
+// Link is a link of a repository, such as a clone URL named http or ssh.
+type Link struct {
+	Href string `json:"href"`
+	Name string `json:"name,omitempty"`
+}
+
+type RepositoryLinks struct {
+	Clone []Link `json:"clone"`
+	Self  []Link `json:"self"`
+}
+
+// RepositoryDetails carries the repository attributes that bitbucket.Repository
+// does not map.
+type RepositoryDetails struct {
+	bitbucket.Repository
+	ScmId    string             `json:"scmId"`
+	State    string             `json:"state"`
+	Archived bool               `json:"archived"`
+	Forkable bool               `json:"forkable"`
+	Public   bool               `json:"public"`
+	Links    RepositoryLinks    `json:"links"`
+	Origin   *RepositoryDetails `json:"origin,omitempty"`
+}
+
+// cloneUrl returns the clone link with the given name, without the user name
+// that Bitbucket puts into http links for the authenticated user.
+func (repository RepositoryDetails) cloneUrl(name string) string {
+	for _, link := range repository.Links.Clone {
+		if link.Name != name {
+			continue
+		}
+
+		parsed, err := url.Parse(link.Href)
+		if err != nil || name != "http" {
+			return link.Href
+		}
+
+		parsed.User = nil
+		return parsed.String()
+	}
+
+	return ""
+}
+
+func (repository RepositoryDetails) HttpCloneUrl() string {
+	return repository.cloneUrl("http")
+}
+
+func (repository RepositoryDetails) SshCloneUrl() string {
+	return repository.cloneUrl("ssh")
+}
+
+func (repository RepositoryDetails) BrowseUrl() string {
+	if len(repository.Links.Self) == 0 {
+		return ""
+	}
+
+	return repository.Links.Self[0].Href
+}
+
+// RepositorySettings is the payload to create or update a repository. Nil values
+// are left to the server.
+type RepositorySettings struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description"`
+	Forkable    *bool  `json:"forkable,omitempty"`
+	Public      *bool  `json:"public,omitempty"`
+}
+
+func repositoryEndPoint(project, repo string) string {
+	return fmt.Sprintf("/rest/api/latest/projects/%s/repos/%s", url.PathEscape(project), url.PathEscape(repo))
+}
+
+func (extension *ClientExtension) CreateRepository(project string, settings RepositorySettings) (*RepositoryDetails, error) {
+	reply, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodPost,
+		Url:    fmt.Sprintf("/rest/api/latest/projects/%s/repos", url.PathEscape(project)),
+		Payload: &transport.JsonPayloadData{
+			Payload: settings,
+		},
+	}, 201)
+	if err != nil {
+		return nil, err
+	}
+
+	response := RepositoryDetails{}
+	err = reply.Object(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (extension *ClientExtension) UpdateRepository(project, repo string, settings RepositorySettings) (*RepositoryDetails, error) {
+	reply, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodPut,
+		Url:    repositoryEndPoint(project, repo),
+		Payload: &transport.JsonPayloadData{
+			Payload: settings,
+		},
+	}, 200, 201)
+	if err != nil {
+		return nil, err
+	}
+
+	response := RepositoryDetails{}
+	err = reply.Object(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// MoveRepository moves the repository into another project. Bitbucket keeps the
+// repository id, history and pull requests.
+func (extension *ClientExtension) MoveRepository(project, repo, newProject string) (*bitbucket.Repository, error) {
+	reply, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodPut,
+		Url:    repositoryEndPoint(project, repo),
+		Payload: &transport.JsonPayloadData{
+			Payload: map[string]any{
+				"project": map[string]string{
+					"key": newProject,
+				},
+			},
+		},
+	}, 200, 201)
+	if err != nil {
+		return nil, err
+	}
+
+	response := bitbucket.Repository{}
+	err = reply.Object(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (extension *ClientExtension) ReadRepository(project, repo string) (*RepositoryDetails, error) {
+	reply, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodGet,
+		Url:    repositoryEndPoint(project, repo),
+	}, 200)
+	if err != nil {
+		return nil, err
+	}
+
+	response := RepositoryDetails{}
+	err = reply.Object(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ArchiveRepository archives or unarchives the repository. Archived repositories
+// are read-only. Requires Bitbucket 8.0 or later.
+func (extension *ClientExtension) ArchiveRepository(project, repo string, archived bool) error {
+	_, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodPut,
+		Url:    repositoryEndPoint(project, repo),
+		Payload: &transport.JsonPayloadData{
+			Payload: map[string]bool{
+				"archived": archived,
+			},
+		},
+	}, 200, 201)
+	return err
+}
+
+type Ref struct {
+	Id        string `json:"id"`
+	DisplayId string `json:"displayId,omitempty"`
+}
+
+// GetDefaultBranch returns the name of the default branch, or an empty string
+// when the repository has none yet.
+func (extension *ClientExtension) GetDefaultBranch(project, repo string) (string, error) {
+	reply, err := extension.transport.Send(&transport.PayloadRequest{
+		Method: http.MethodGet,
+		Url:    repositoryEndPoint(project, repo) + "/default-branch",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	switch reply.StatusCode {
+	case http.StatusOK:
+		response := Ref{}
+		err = reply.Object(&response)
+		if err != nil {
+			return "", err
+		}
+
+		return response.DisplayId, nil
+	case http.StatusNoContent, http.StatusNotFound:
+		return "", nil
+	default:
+		return "", fmt.Errorf("unexpected status %d: %s", reply.StatusCode, reply.Body)
+	}
+}
+
+func (extension *ClientExtension) SetDefaultBranch(project, repo, branch string) error {
+	_, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodPut,
+		Url:    repositoryEndPoint(project, repo) + "/default-branch",
+		Payload: &transport.JsonPayloadData{
+			Payload: Ref{
+				Id: branchRefId(branch),
+			},
+		},
+	}, 200, 204)
+	return err
+}
+
+// ForkRepository forks the repository into the target project. The fork may still
+// be initialising when this returns, see RepositoryDetails.State.
+func (extension *ClientExtension) ForkRepository(project, repo, targetProject, name string) (*RepositoryDetails, error) {
+	reply, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodPost,
+		Url:    repositoryEndPoint(project, repo),
+		Payload: &transport.JsonPayloadData{
+			Payload: map[string]any{
+				"name": name,
+				"project": map[string]string{
+					"key": targetProject,
+				},
+			},
+		},
+	}, 201)
+	if err != nil {
+		return nil, err
+	}
+
+	response := RepositoryDetails{}
+	err = reply.Object(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+type RefSync struct {
+	Available bool `json:"available"`
+	Enabled   bool `json:"enabled"`
+}
+
+func refSyncEndPoint(project, repo string) string {
+	return fmt.Sprintf("/rest/sync/latest/projects/%s/repos/%s", url.PathEscape(project), url.PathEscape(repo))
+}
+
+// GetRefSync returns the ref synchronization status of a fork.
+func (extension *ClientExtension) GetRefSync(project, repo string) (*RefSync, error) {
+	reply, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodGet,
+		Url:    refSyncEndPoint(project, repo),
+	}, 200)
+	if err != nil {
+		return nil, err
+	}
+
+	response := RefSync{}
+	err = reply.Object(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// SetRefSync enables or disables keeping the fork in sync with its origin.
+func (extension *ClientExtension) SetRefSync(project, repo string, enabled bool) error {
+	_, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodPost,
+		Url:    refSyncEndPoint(project, repo),
+		Payload: &transport.JsonPayloadData{
+			Payload: map[string]bool{
+				"enabled": enabled,
+			},
+		},
+	}, 200)
+	return err
+}
+
+// errRefExists is returned when a branch or tag with the same name already
+// exists.
+var errRefExists = errors.New("the ref already exists")
+
+// CreateBranch creates a branch that starts at the given commit, errRefExists is
+// returned when the branch exists.
+func (extension *ClientExtension) CreateBranch(project, repo, name, startPoint string) error {
+	return extension.createRef(repositoryEndPoint(project, repo)+"/branches", name, startPoint)
+}
+
+// CreateTag creates a lightweight tag that points at the given commit,
+// errRefExists is returned when the tag exists.
+func (extension *ClientExtension) CreateTag(project, repo, name, startPoint string) error {
+	return extension.createRef(repositoryEndPoint(project, repo)+"/tags", name, startPoint)
+}
+
+func (extension *ClientExtension) createRef(endPoint, name, startPoint string) error {
+	reply, err := extension.transport.Send(&transport.PayloadRequest{
+		Method: http.MethodPost,
+		Url:    endPoint,
+		Payload: &transport.JsonPayloadData{
+			Payload: map[string]string{
+				"name":       name,
+				"startPoint": startPoint,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	switch reply.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		return nil
+	case http.StatusConflict:
+		return errRefExists
+	default:
+		return fmt.Errorf("unexpected status %d: %s", reply.StatusCode, reply.Body)
+	}
+}
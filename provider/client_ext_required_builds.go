@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"fmt"
+	"github.com/yunarta/terraform-api-transport/transport"
+	"net/http"
+	"net/url"
+)
+
+type RefMatcherType struct {
+	Id   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+type RefMatcher struct {
+	Id        string         `json:"id"`
+	DisplayId string         `json:"displayId,omitempty"`
+	Type      RefMatcherType `json:"type"`
+}
+
+type RequiredBuildCondition struct {
+	Id               int64       `json:"id,omitempty"`
+	BuildParentKeys  []string    `json:"buildParentKeys"`
+	RefMatcher       RefMatcher  `json:"refMatcher"`
+	ExemptRefMatcher *RefMatcher `json:"exemptRefMatcher,omitempty"`
+}
+
+// requiredBuildsBase returns the required builds base path for a project, or for
+// a repository when repo is not empty. Requires Bitbucket 7.14 or later.
+func requiredBuildsBase(project, repo string) string {
+	if repo == "" {
+		return fmt.Sprintf("/rest/required-builds/latest/projects/%s", url.PathEscape(project))
+	}
+
+	return fmt.Sprintf("/rest/required-builds/latest/projects/%s/repos/%s",
+		url.PathEscape(project), url.PathEscape(repo))
+}
+
+// requiredBuildsEndPoint returns the end point that lists the conditions.
+func requiredBuildsEndPoint(project, repo string) string {
+	return requiredBuildsBase(project, repo) + "/conditions"
+}
+
+// requiredBuildConditionEndPoint returns the end point that creates a condition,
+// and with its id, updates or deletes it.
+func requiredBuildConditionEndPoint(project, repo string) string {
+	return requiredBuildsBase(project, repo) + "/condition"
+}
+
+func (extension *ClientExtension) CreateRequiredBuildCondition(project, repo string, condition RequiredBuildCondition) (*RequiredBuildCondition, error) {
+	reply, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodPost,
+		Url:    requiredBuildConditionEndPoint(project, repo),
+		Payload: &transport.JsonPayloadData{
+			Payload: condition,
+		},
+	}, 200, 201)
+	if err != nil {
+		return nil, err
+	}
+
+	response := RequiredBuildCondition{}
+	err = reply.Object(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ReadRequiredBuildCondition returns the condition with the given id, or nil
+// when it no longer exists.
+func (extension *ClientExtension) ReadRequiredBuildCondition(project, repo string, id int64) (*RequiredBuildCondition, error) {
+	conditions, err := getAllPages[RequiredBuildCondition](extension.transport, requiredBuildsEndPoint(project, repo))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, condition := range conditions {
+		if condition.Id == id {
+			return &condition, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (extension *ClientExtension) UpdateRequiredBuildCondition(project, repo string, id int64, condition RequiredBuildCondition) (*RequiredBuildCondition, error) {
+	reply, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodPut,
+		Url:    fmt.Sprintf("%s/%d", requiredBuildConditionEndPoint(project, repo), id),
+		Payload: &transport.JsonPayloadData{
+			Payload: condition,
+		},
+	}, 200)
+	if err != nil {
+		return nil, err
+	}
+
+	response := RequiredBuildCondition{}
+	err = reply.Object(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (extension *ClientExtension) DeleteRequiredBuildCondition(project, repo string, id int64) error {
+	_, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodDelete,
+		Url:    fmt.Sprintf("%s/%d", requiredBuildConditionEndPoint(project, repo), id),
+	}, 200, 204)
+	return err
+}
@@ -0,0 +1,152 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"github.com/yunarta/terraform-api-transport/transport"
+	"github.com/yunarta/terraform-atlassian-api-client/bitbucket"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type ReviewerGroupScope struct {
+	Type       string `json:"type,omitempty"`
+	ResourceId int64  `json:"resourceId,omitempty"`
+}
+
+type ReviewerGroup struct {
+	Id          int64              `json:"id,omitempty"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Scope       ReviewerGroupScope `json:"scope,omitempty"`
+	Users       []bitbucket.User   `json:"users"`
+}
+
+// reviewerGroupsEndPoint returns the reviewer groups end point for a project, or
+// for a repository when repo is not empty. Requires Bitbucket 7.13 or later.
+func reviewerGroupsEndPoint(project, repo string) string {
+	if repo == "" {
+		return fmt.Sprintf("/rest/api/latest/projects/%s/settings/reviewer-groups", url.PathEscape(project))
+	}
+
+	return fmt.Sprintf("/rest/api/latest/projects/%s/repos/%s/settings/reviewer-groups",
+		url.PathEscape(project), url.PathEscape(repo))
+}
+
+// FindReviewerGroup looks up a reviewer group by name, or returns nil when the
+// scope has no such group.
+func (extension *ClientExtension) FindReviewerGroup(project, repo, name string) (*ReviewerGroup, error) {
+	groups, err := getAllPages[ReviewerGroup](extension.transport, reviewerGroupsEndPoint(project, repo))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		if strings.EqualFold(group.Name, name) {
+			return &group, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// errGroupMembersForbidden is returned when the credentials of the provider may
+// not list the members of a user group.
+var errGroupMembersForbidden = errors.New("listing the members of a user group requires admin access")
+
+// FindGroupMembers returns the members of a user group. Requires admin access,
+// errGroupMembersForbidden is returned without it.
+func (extension *ClientExtension) FindGroupMembers(group string) ([]bitbucket.User, error) {
+	endPoint := fmt.Sprintf("/rest/api/latest/admin/groups/more-members?context=%s", url.QueryEscape(group))
+
+	reply, err := extension.transport.Send(&transport.PayloadRequest{
+		Method: http.MethodGet,
+		Url:    endPoint + "&limit=1",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if reply.StatusCode == http.StatusUnauthorized || reply.StatusCode == http.StatusForbidden {
+		return nil, errGroupMembersForbidden
+	}
+
+	return getAllPages[bitbucket.User](extension.transport, endPoint)
+}
+
+func (extension *ClientExtension) CreateReviewerGroup(project, repo string, group ReviewerGroup) (*ReviewerGroup, error) {
+	reply, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodPost,
+		Url:    reviewerGroupsEndPoint(project, repo),
+		Payload: &transport.JsonPayloadData{
+			Payload: group,
+		},
+	}, 200, 201)
+	if err != nil {
+		return nil, err
+	}
+
+	response := ReviewerGroup{}
+	err = reply.Object(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// ReadReviewerGroup returns the reviewer group, or nil when it no longer exists.
+func (extension *ClientExtension) ReadReviewerGroup(project, repo string, id int64) (*ReviewerGroup, error) {
+	reply, err := extension.transport.Send(&transport.PayloadRequest{
+		Method: http.MethodGet,
+		Url:    fmt.Sprintf("%s/%d", reviewerGroupsEndPoint(project, repo), id),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch reply.StatusCode {
+	case http.StatusOK:
+		response := ReviewerGroup{}
+		err = reply.Object(&response)
+		if err != nil {
+			return nil, err
+		}
+
+		return &response, nil
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unexpected status %d: %s", reply.StatusCode, reply.Body)
+	}
+}
+
+func (extension *ClientExtension) UpdateReviewerGroup(project, repo string, id int64, group ReviewerGroup) (*ReviewerGroup, error) {
+	reply, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodPut,
+		Url:    fmt.Sprintf("%s/%d", reviewerGroupsEndPoint(project, repo), id),
+		Payload: &transport.JsonPayloadData{
+			Payload: group,
+		},
+	}, 200)
+	if err != nil {
+		return nil, err
+	}
+
+	response := ReviewerGroup{}
+	err = reply.Object(&response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (extension *ClientExtension) DeleteReviewerGroup(project, repo string, id int64) error {
+	_, err := extension.transport.SendWithExpectedStatus(&transport.PayloadRequest{
+		Method: http.MethodDelete,
+		Url:    fmt.Sprintf("%s/%d", reviewerGroupsEndPoint(project, repo), id),
+	}, 200, 204)
+	return err
+}
@@ -0,0 +1,243 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/crypto/ssh"
+	"io"
+	"strings"
+)
+
+// commitSigner signs the commits the provider creates with the key configured in
+// commit_signing.
+type commitSigner struct {
+	signer      git.Signer
+	fingerprint string
+}
+
+// newCommitSigner returns the signer of the provider, or nil when commit_signing
+// is not configured. Armored OpenPGP private keys and OpenSSH private keys are
+// supported.
+func newCommitSigner(config BitbucketProviderConfig) (*commitSigner, error) {
+	if config.CommitSigning == nil || config.CommitSigning.KeyArmoredWo.IsNull() {
+		return nil, nil
+	}
+
+	key := config.CommitSigning.KeyArmoredWo.ValueString()
+	passphrase := config.CommitSigning.PassphraseWo.ValueString()
+	if strings.Contains(key, "BEGIN PGP PRIVATE KEY BLOCK") {
+		return newOpenPGPSigner(key, passphrase)
+	}
+
+	return newSSHSigner(key, passphrase)
+}
+
+func newOpenPGPSigner(key string, passphrase string) (*commitSigner, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the OpenPGP key: %w", err)
+	}
+
+	var entity *openpgp.Entity
+	for _, candidate := range entities {
+		if candidate.PrivateKey != nil {
+			entity = candidate
+			break
+		}
+	}
+	if entity == nil {
+		return nil, errors.New("the OpenPGP key has no private key")
+	}
+
+	if passphrase != "" {
+		err = entity.DecryptPrivateKeys([]byte(passphrase))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt the OpenPGP key: %w", err)
+		}
+	} else if entity.PrivateKey.Encrypted {
+		return nil, errors.New("the OpenPGP key is encrypted, passphrase_wo is required")
+	}
+
+	return &commitSigner{
+		signer:      &openPGPSigner{entity: entity},
+		fingerprint: strings.ToUpper(hex.EncodeToString(entity.PrimaryKey.Fingerprint)),
+	}, nil
+}
+
+func newSSHSigner(key string, passphrase string) (*commitSigner, error) {
+	var (
+		signer ssh.Signer
+		err    error
+	)
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(key), []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey([]byte(key))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the signing key, expected an armored OpenPGP or OpenSSH private key: %w", err)
+	}
+
+	return &commitSigner{
+		signer:      &sshSigner{signer: signer},
+		fingerprint: ssh.FingerprintSHA256(signer.PublicKey()),
+	}, nil
+}
+
+// commitOptions returns the options for a commit by the provider author, signed
+// when commit_signing is configured.
+func commitOptions(config BitbucketProviderConfig) (*git.CommitOptions, error) {
+	signer, err := newCommitSigner(config)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &git.CommitOptions{
+		Author: authorSignature(config),
+	}
+	if signer != nil {
+		options.Signer = signer.signer
+	}
+
+	return options, nil
+}
+
+// storeCommit signs a commit object created without a worktree, and stores it in
+// the repository.
+func storeCommit(config BitbucketProviderConfig, repo *git.Repository, commit *object.Commit) (plumbing.Hash, error) {
+	signer, err := newCommitSigner(config)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	if signer != nil {
+		unsigned := &plumbing.MemoryObject{}
+		err = commit.EncodeWithoutSignature(unsigned)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+
+		reader, err := unsigned.Reader()
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+
+		signature, err := signer.signer.Sign(reader)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		commit.PGPSignature = string(signature)
+	}
+
+	encoded := repo.Storer.NewEncodedObject()
+	err = commit.Encode(encoded)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return repo.Storer.SetEncodedObject(encoded)
+}
+
+// signingKeyFingerprint returns the fingerprint of the commit_signing key, or
+// null when commits are not signed.
+func signingKeyFingerprint(config BitbucketProviderConfig) types.String {
+	signer, err := newCommitSigner(config)
+	if err != nil || signer == nil {
+		return types.StringNull()
+	}
+
+	return types.StringValue(signer.fingerprint)
+}
+
+type openPGPSigner struct {
+	entity *openpgp.Entity
+}
+
+func (signer *openPGPSigner) Sign(message io.Reader) ([]byte, error) {
+	var signature bytes.Buffer
+	err := openpgp.ArmoredDetachSign(&signature, signer.entity, message, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return signature.Bytes(), nil
+}
+
+// sshSigner creates signatures in the SSHSIG format of ssh-keygen -Y sign, which
+// git uses with gpg.format=ssh.
+type sshSigner struct {
+	signer ssh.Signer
+}
+
+const (
+	sshSigMagic         = "SSHSIG"
+	sshSigNamespace     = "git"
+	sshSigHashAlgorithm = "sha512"
+)
+
+func (signer *sshSigner) Sign(message io.Reader) ([]byte, error) {
+	hash := sha512.New()
+	_, err := io.Copy(hash, message)
+	if err != nil {
+		return nil, err
+	}
+
+	signedData := bytes.NewBufferString(sshSigMagic)
+	writeSSHString(signedData, []byte(sshSigNamespace))
+	writeSSHString(signedData, nil)
+	writeSSHString(signedData, []byte(sshSigHashAlgorithm))
+	writeSSHString(signedData, hash.Sum(nil))
+
+	var signature *ssh.Signature
+	if algorithmSigner, ok := signer.signer.(ssh.AlgorithmSigner); ok && signer.signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		// ssh-rsa signatures use SHA-1, which is not accepted for SSHSIG
+		signature, err = algorithmSigner.SignWithAlgorithm(rand.Reader, signedData.Bytes(), ssh.KeyAlgoRSASHA512)
+	} else {
+		signature, err = signer.signer.Sign(rand.Reader, signedData.Bytes())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	blob := bytes.NewBufferString(sshSigMagic)
+	_ = binary.Write(blob, binary.BigEndian, uint32(1))
+	writeSSHString(blob, signer.signer.PublicKey().Marshal())
+	writeSSHString(blob, []byte(sshSigNamespace))
+	writeSSHString(blob, nil)
+	writeSSHString(blob, []byte(sshSigHashAlgorithm))
+	writeSSHString(blob, ssh.Marshal(signature))
+
+	encoded := base64.StdEncoding.EncodeToString(blob.Bytes())
+
+	var armored strings.Builder
+	armored.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(encoded) > 70 {
+		armored.WriteString(encoded[:70] + "\n")
+		encoded = encoded[70:]
+	}
+	armored.WriteString(encoded + "\n")
+	armored.WriteString("-----END SSH SIGNATURE-----\n")
+
+	return []byte(armored.String()), nil
+}
+
+func writeSSHString(buffer *bytes.Buffer, value []byte) {
+	_ = binary.Write(buffer, binary.BigEndian, uint32(len(value)))
+	buffer.Write(value)
+}
+
+var (
+	_ git.Signer = &openPGPSigner{}
+	_ git.Signer = &sshSigner{}
+)
@@ -5,8 +5,24 @@ const errorFailedToUpdateState = "Failed to update resource state"
 const errorFailedToCreateRepository = "Failed to create repository"
 const errorFailedToReadRepository = "Failed to read repository"
 const errorFailedToUpdateRepository = "Failed to update repository"
+const errorFailedToRenameRepository = "Failed to rename repository"
+const errorFailedToMoveRepository = "Failed to move repository"
+const errorFailedToArchiveRepository = "Failed to archive repository"
+const errorFailedToUnarchiveRepository = "Failed to unarchive repository"
 const errorFailedToDeleteRepository = "Failed to delete repository"
+const errorFailedToForkRepository = "Failed to fork repository"
+const errorFailedToReadRefSync = "Failed to read ref synchronization"
+const errorFailedToUpdateRefSync = "Failed to update ref synchronization"
 const errorFailedToInitializeRepository = "Failed to initialize repository"
+const errorFailedToImportRepository = "Failed to import repository"
+const errorFailedToReadDefaultBranch = "Failed to read default branch"
+const errorFailedToSetDefaultBranch = "Failed to set default branch"
+
+const errorFailedToReadFile = "Failed to read repository file"
+const errorFailedToCommitFile = "Failed to commit repository file"
+const errorFailedToDeleteFile = "Failed to delete repository file"
+const errorFailedToReadDirectory = "Failed to read repository directory"
+const errorFailedToSyncDirectory = "Failed to sync repository directory"
 
 const errorFailedToReadProjectPermission = "Failed to read project permissions"
 const errorFailedToReadRepositoryPermission = "Failed to read repository permissions"
@@ -1,5 +1,13 @@
 package provider
 
+import (
+	"errors"
+	"github.com/yunarta/terraform-atlassian-api-client/bitbucket"
+	"slices"
+	"sort"
+	"strings"
+)
+
 var refTypes = []string{"any", "pattern", "branch"}
 var refTypesMap = map[string]string{
 	"any":     "ANY_REF",
@@ -11,3 +19,144 @@ var refTypesReverseMap = map[string]string{
 	"PATTERN": "pattern",
 	"BRANCH":  "branch",
 }
+
+type ResolvedReviewers struct {
+	Users      []bitbucket.User
+	GroupUsers []bitbucket.User
+	Unresolved []string
+	// Unexpanded lists the user groups whose members could not be listed
+	Unexpanded []string
+}
+
+// Reviewers returns the distinct users from both the reviewers and the groups.
+func (r ResolvedReviewers) Reviewers() []bitbucket.User {
+	seen := make(map[int64]bool)
+	reviewers := make([]bitbucket.User, 0)
+	for _, user := range append(append([]bitbucket.User{}, r.Users...), r.GroupUsers...) {
+		if !seen[user.Id] {
+			seen[user.Id] = true
+			reviewers = append(reviewers, bitbucket.User{
+				Id: user.Id,
+			})
+		}
+	}
+
+	return reviewers
+}
+
+// ResolveReviewers finds the users behind the configured reviewers and reviewer
+// groups. A group is looked up as a repository reviewer group, then as a project
+// reviewer group, and finally as a user group whose members are expanded.
+// Names that cannot be resolved are collected instead of being dropped, and so
+// are user groups whose members the provider is not allowed to list.
+func ResolveReviewers(client *bitbucket.Client, extension *ClientExtension, project, repo string, reviewers []string, groups []string) (*ResolvedReviewers, error) {
+	resolved := &ResolvedReviewers{
+		Users:      make([]bitbucket.User, 0),
+		GroupUsers: make([]bitbucket.User, 0),
+		Unresolved: make([]string, 0),
+		Unexpanded: make([]string, 0),
+	}
+
+	for _, user := range reviewers {
+		found, err := client.UserService().FindUser(user)
+		if err != nil {
+			return nil, err
+		}
+
+		if found == nil {
+			resolved.Unresolved = append(resolved.Unresolved, user)
+		} else {
+			resolved.Users = append(resolved.Users, *found)
+		}
+	}
+
+	for _, group := range groups {
+		members, err := findReviewerGroupMembers(client, extension, project, repo, group)
+		if errors.Is(err, errGroupMembersForbidden) {
+			resolved.Unexpanded = append(resolved.Unexpanded, group)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if members == nil {
+			resolved.Unresolved = append(resolved.Unresolved, group)
+		} else {
+			resolved.GroupUsers = append(resolved.GroupUsers, members...)
+		}
+	}
+
+	return resolved, nil
+}
+
+func findReviewerGroupMembers(client *bitbucket.Client, extension *ClientExtension, project, repo, group string) ([]bitbucket.User, error) {
+	if repo != "" {
+		reviewerGroup, err := extension.FindReviewerGroup(project, repo, group)
+		if err != nil {
+			return nil, err
+		}
+
+		if reviewerGroup != nil {
+			return reviewerGroup.Users, nil
+		}
+	}
+
+	reviewerGroup, err := extension.FindReviewerGroup(project, "", group)
+	if err != nil {
+		return nil, err
+	}
+
+	if reviewerGroup != nil {
+		return reviewerGroup.Users, nil
+	}
+
+	userGroup, err := client.UserService().FindGroup(group)
+	if err != nil || userGroup == nil {
+		return nil, err
+	}
+
+	return extension.FindGroupMembers(userGroup.Name)
+}
+
+// ReadReviewers maps the reviewers on the server back to the configured names.
+// Configured reviewers that are on the server, or that could not be resolved,
+// are kept; reviewers that are neither configured nor a member of a configured
+// group are appended so that out-of-band additions show up as a diff. They are
+// not detected while a group could not be expanded, as its members are unknown.
+func ReadReviewers(serverReviewers []bitbucket.User, configured []string, resolved *ResolvedReviewers) []string {
+	onServer := make(map[string]bool)
+	for _, user := range serverReviewers {
+		onServer[strings.ToLower(user.Name)] = true
+	}
+
+	explained := make(map[string]bool)
+	for _, user := range resolved.GroupUsers {
+		explained[strings.ToLower(user.Name)] = true
+	}
+
+	reviewers := make([]string, 0)
+	for _, user := range configured {
+		explained[strings.ToLower(user)] = true
+		if onServer[strings.ToLower(user)] || slices.Contains(resolved.Unresolved, user) {
+			reviewers = append(reviewers, user)
+		}
+	}
+
+	additional := make([]string, 0)
+	for _, user := range serverReviewers {
+		if len(resolved.Unexpanded) > 0 {
+			break
+		}
+
+		if !explained[strings.ToLower(user.Name)] {
+			additional = append(additional, user.Name)
+		}
+	}
+	sort.Strings(additional)
+
+	if configured == nil && len(additional) == 0 {
+		return nil
+	}
+
+	return append(reviewers, additional...)
+}
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"reflect"
+)
+
+// jsonSemanticEqual reports whether both strings decode to the same JSON value,
+// ignoring formatting and key order.
+func jsonSemanticEqual(a, b string) bool {
+	var left, right any
+	if json.Unmarshal([]byte(a), &left) != nil || json.Unmarshal([]byte(b), &right) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(left, right)
+}
+
+// normalizeJson re-encodes the JSON string with sorted keys and no whitespace.
+func normalizeJson(value string) (string, error) {
+	var decoded any
+	err := json.Unmarshal([]byte(value), &decoded)
+	if err != nil {
+		return "", err
+	}
+
+	normalized, err := json.Marshal(decoded)
+	if err != nil {
+		return "", err
+	}
+
+	return string(normalized), nil
+}
+
+type jsonSemanticEquality struct {
+}
+
+func (r jsonSemanticEquality) Description(ctx context.Context) string {
+	return "Keeps the value in state when the configured JSON is semantically equal to it."
+}
+
+func (r jsonSemanticEquality) MarkdownDescription(ctx context.Context) string {
+	return "Keeps the value in state when the configured JSON is semantically equal to it."
+}
+
+func (r jsonSemanticEquality) PlanModifyString(ctx context.Context, request planmodifier.StringRequest, response *planmodifier.StringResponse) {
+	if request.ConfigValue.IsNull() || request.ConfigValue.IsUnknown() {
+		if request.ConfigValue.IsNull() && !request.StateValue.IsNull() {
+			response.PlanValue = request.StateValue
+		}
+		return
+	}
+
+	if request.StateValue.IsNull() || request.StateValue.IsUnknown() {
+		return
+	}
+
+	if jsonSemanticEqual(request.ConfigValue.ValueString(), request.StateValue.ValueString()) {
+		response.PlanValue = request.StateValue
+	}
+}
+
+var _ planmodifier.String = &jsonSemanticEquality{}
+
+type jsonStringValidator struct {
+}
+
+func (v jsonStringValidator) Description(ctx context.Context) string {
+	return "value must be a valid JSON object"
+}
+
+func (v jsonStringValidator) MarkdownDescription(ctx context.Context) string {
+	return "value must be a valid JSON object"
+}
+
+func (v jsonStringValidator) ValidateString(ctx context.Context, request validator.StringRequest, response *validator.StringResponse) {
+	if request.ConfigValue.IsNull() || request.ConfigValue.IsUnknown() {
+		return
+	}
+
+	var decoded map[string]any
+	err := json.Unmarshal([]byte(request.ConfigValue.ValueString()), &decoded)
+	if err != nil {
+		response.Diagnostics.AddAttributeError(request.Path,
+			"Invalid JSON",
+			fmt.Sprintf("Value must be a JSON object: %s", err.Error()),
+		)
+	}
+}
+
+var _ validator.String = &jsonStringValidator{}
@@ -0,0 +1,218 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// localTreeFilter skips .git, the patterns of the .gitignore files in the tree
+// and additional exclude globs, which use the .gitignore syntax as well.
+type localTreeFilter struct {
+	matcher gitignore.Matcher
+}
+
+func newLocalTreeFilter(root string, exclude []string) (*localTreeFilter, error) {
+	patterns, err := gitignore.ReadPatterns(osfs.New(root), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns = append(patterns, gitignore.ParsePattern(".git", nil))
+	for _, pattern := range exclude {
+		patterns = append(patterns, gitignore.ParsePattern(pattern, nil))
+	}
+
+	return &localTreeFilter{
+		matcher: gitignore.NewMatcher(patterns),
+	}, nil
+}
+
+// walkLocalTree calls fn for every file and symlink below root that passes the
+// filter, with the slash separated path relative to root. Symlinks are not
+// followed.
+func walkLocalTree(root string, filter *localTreeFilter, fn func(relativePath string, file string, info os.FileInfo) error) error {
+	return filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		relativePath = filepath.ToSlash(relativePath)
+
+		if relativePath != "." && filter != nil && filter.matcher.Match(strings.Split(relativePath, "/"), info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.IsDir() {
+			return nil // skip directories
+		}
+
+		return fn(relativePath, file, info)
+	})
+}
+
+// localTreeSize returns the total size of the regular files that walkLocalTree
+// visits.
+func localTreeSize(root string, filter *localTreeFilter) (int64, error) {
+	var size int64
+	err := walkLocalTree(root, filter, func(relativePath string, file string, info os.FileInfo) error {
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+
+	return size, err
+}
+
+// copyToWorktree copies a local file into the worktree file system, keeping its
+// permissions so that executables stay executable, and symlinks as symlinks.
+func copyToWorktree(filesystem billy.Filesystem, file string, info os.FileInfo, destPath string) error {
+	_, err := copyAndHashToWorktree(filesystem, file, info, destPath)
+	return err
+}
+
+// copyAndHashToWorktree copies a local file like copyToWorktree, and returns
+// its SHA-256 the same way hashLocalFile does, reading the file once.
+func copyAndHashToWorktree(filesystem billy.Filesystem, file string, info os.FileInfo, destPath string) (string, error) {
+	// a worktree cloned from the branch may hold the file already, whose mode an
+	// open keeps and on which a symlink fails, so it is replaced
+	err := filesystem.Remove(destPath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(file)
+		if err != nil {
+			return "", err
+		}
+
+		return contentSha256(target), filesystem.Symlink(target, destPath)
+	}
+
+	srcFile, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer srcFile.Close()
+
+	destFile, err := filesystem.OpenFile(destPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(destFile, hash), srcFile)
+	if err != nil {
+		destFile.Close()
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), destFile.Close()
+}
+
+func hashReader(reader io.Reader) (string, error) {
+	hash := sha256.New()
+	_, err := io.Copy(hash, reader)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// hashLocalFile returns the SHA-256 of a file, or of the target of a symlink.
+func hashLocalFile(file string, info os.FileInfo) (string, error) {
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(file)
+		if err != nil {
+			return "", err
+		}
+
+		return contentSha256(target), nil
+	}
+
+	reader, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	return hashReader(reader)
+}
+
+// hashWorktreeFile returns the SHA-256 of a worktree file, or of the target of a
+// symlink, the same way hashLocalFile does.
+func hashWorktreeFile(filesystem billy.Filesystem, file string) (string, error) {
+	info, err := filesystem.Lstat(file)
+	if err != nil {
+		return "", err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := filesystem.Readlink(file)
+		if err != nil {
+			return "", err
+		}
+
+		return contentSha256(target), nil
+	}
+
+	reader, err := filesystem.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	return hashReader(reader)
+}
+
+// hashLocalTree returns the SHA-256 of every file below root that passes the
+// filter, keyed by its path in the repository, that is below prefix.
+func hashLocalTree(root string, filter *localTreeFilter, prefix string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := walkLocalTree(root, filter, func(relativePath string, file string, info os.FileInfo) error {
+		hash, err := hashLocalFile(file, info)
+		if err != nil {
+			return err
+		}
+
+		files[path.Join(prefix, relativePath)] = hash
+		return nil
+	})
+
+	return files, err
+}
+
+// treeHash combines the hashes of a set of files into a single digest.
+func treeHash(files map[string]string) string {
+	paths := make([]string, 0, len(files))
+	for file := range files {
+		paths = append(paths, file)
+	}
+	sort.Strings(paths)
+
+	var builder strings.Builder
+	for _, file := range paths {
+		builder.WriteString(fmt.Sprintf("%s %s\n", files[file], file))
+	}
+
+	return contentSha256(builder.String())
+}
@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"strconv"
+)
+
+const (
+	mergeCheckAllApprovers     = "com.atlassian.bitbucket.server.bitbucket-bundled-hooks:all-approvers-merge-check"
+	mergeCheckRequiredApproval = "com.atlassian.bitbucket.server.bitbucket-bundled-hooks:requiredApproversMergeHook"
+	mergeCheckRequiredBuilds   = "com.atlassian.bitbucket.server.bitbucket-build:requiredBuildsMergeCheck"
+)
+
+type ProjectMergeCheckModel struct {
+	Project  types.String `tfsdk:"project"`
+	Key      types.String `tfsdk:"key"`
+	Enabled  types.Bool   `tfsdk:"enabled"`
+	Settings types.String `tfsdk:"settings"`
+}
+
+type RepositoryMergeCheckModel struct {
+	Project  types.String `tfsdk:"project"`
+	Repo     types.String `tfsdk:"repo"`
+	Key      types.String `tfsdk:"key"`
+	Enabled  types.Bool   `tfsdk:"enabled"`
+	Settings types.String `tfsdk:"settings"`
+}
+
+// applyMergeCheck pushes the planned settings, then enables or disables the
+// hook. An empty repo addresses the project scope.
+func applyMergeCheck(extension *ClientExtension, project, repo, key string, enabled types.Bool, settings types.String) error {
+	if !settings.IsNull() && !settings.IsUnknown() {
+		err := extension.UpdateHookSettings(project, repo, key, settings.ValueString())
+		if err != nil {
+			return err
+		}
+	}
+
+	if enabled.ValueBool() {
+		return extension.EnableHook(project, repo, key, "")
+	}
+
+	return extension.DisableHook(project, repo, key)
+}
+
+// readMergeCheck returns the enabled flag and settings from the server. The
+// settings in state are kept as long as they are semantically equal to the
+// server value, so formatting differences do not show up as drift.
+func readMergeCheck(extension *ClientExtension, project, repo, key string, inState types.String) (types.Bool, types.String, error) {
+	hook, err := extension.GetHook(project, repo, key)
+	if err != nil {
+		return types.BoolNull(), types.StringNull(), err
+	}
+
+	settings, err := extension.GetHookSettings(project, repo, key)
+	if err != nil {
+		return types.BoolNull(), types.StringNull(), err
+	}
+
+	if !inState.IsNull() && !inState.IsUnknown() && jsonSemanticEqual(inState.ValueString(), settings) {
+		return types.BoolValue(hook.Enabled), inState, nil
+	}
+
+	normalized, err := normalizeJson(settings)
+	if err != nil {
+		return types.BoolNull(), types.StringNull(), err
+	}
+
+	return types.BoolValue(hook.Enabled), types.StringValue(normalized), nil
+}
+
+// readRequiredCount extracts the requiredCount value from the hook settings,
+// which Bitbucket returns either as a string or as a number. Settings without a
+// count, such as those of a check enabled in the UI, report the count as unset.
+func readRequiredCount(settings string) (int64, bool, error) {
+	var decoded map[string]any
+	err := json.Unmarshal([]byte(settings), &decoded)
+	if err != nil {
+		return 0, false, err
+	}
+
+	switch value := decoded["requiredCount"].(type) {
+	case float64:
+		return int64(value), true, nil
+	case string:
+		if value == "" {
+			return 0, false, nil
+		}
+
+		count, err := strconv.ParseInt(value, 10, 64)
+		return count, err == nil, err
+	default:
+		return 0, false, nil
+	}
+}
+
+const (
+	mergeCheckModeInherit  = "inherit"
+	mergeCheckModeEnabled  = "enabled"
+	mergeCheckModeDisabled = "disabled"
+)
+
+var mergeCheckModes = []string{mergeCheckModeInherit, mergeCheckModeEnabled, mergeCheckModeDisabled}
+
+var mergeCheckModeSchema = schema.StringAttribute{
+	Optional: true,
+	Validators: []validator.String{
+		stringvalidator.OneOf(mergeCheckModes...),
+	},
+	Description: "Repository state of the check: inherit from the project, enabled or disabled",
+}
+
+// validateMergeCheckMode rejects a value attribute that contradicts its mode.
+func validateMergeCheckMode(diagnostics *diag.Diagnostics, attribute string, mode types.String, valueUnset bool, valueRequired bool) {
+	if mode.IsNull() || mode.IsUnknown() {
+		return
+	}
+
+	switch mode.ValueString() {
+	case mergeCheckModeInherit, mergeCheckModeDisabled:
+		if !valueUnset {
+			diagnostics.AddAttributeError(path.Root(attribute),
+				"Invalid Configuration",
+				fmt.Sprintf("'%s' must not be set when '%s_mode' is %s.", attribute, attribute, mode.ValueString()),
+			)
+		}
+	case mergeCheckModeEnabled:
+		if valueRequired && valueUnset {
+			diagnostics.AddAttributeError(path.Root(attribute),
+				"Invalid Configuration",
+				fmt.Sprintf("'%s' must be set when '%s_mode' is enabled.", attribute, attribute),
+			)
+		}
+	}
+}
+
+// readMergeCheckMode maps the repository hook state to a mode. A hook whose
+// scope is the project is inherited. A hook that was never touched on either
+// level has no explicit state, so an inherit mode in state is kept for it.
+func readMergeCheckMode(hook *Hook, inState types.String) types.String {
+	if hook.Scope.Type == "PROJECT" {
+		return types.StringValue(mergeCheckModeInherit)
+	}
+
+	if hook.Enabled {
+		return types.StringValue(mergeCheckModeEnabled)
+	}
+
+	if inState.ValueString() == mergeCheckModeInherit && !hook.Configured {
+		return inState
+	}
+
+	return types.StringValue(mergeCheckModeDisabled)
+}
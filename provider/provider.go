@@ -2,10 +2,14 @@ package provider
 
 import (
 	"context"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/yunarta/terraform-api-transport/transport"
 	"github.com/yunarta/terraform-atlassian-api-client/bitbucket"
 	"os"
@@ -23,6 +27,47 @@ func (p *BitbucketProvider) Metadata(ctx context.Context, request provider.Metad
 
 func (p *BitbucketProvider) Schema(ctx context.Context, request provider.SchemaRequest, response *provider.SchemaResponse) {
 	response.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"git_transport": schema.StringAttribute{
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(gitTransportHttp, gitTransportSsh),
+				},
+				Description: "Transport of the git operations of the provider, http or ssh. Defaults to http",
+			},
+			"ssh_private_key_wo": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "OpenSSH private key used when git_transport is ssh",
+			},
+			"known_hosts": schema.StringAttribute{
+				Optional:    true,
+				Description: "Content of a known_hosts file that verifies the ssh host key. The known_hosts files of the user are used when not set",
+			},
+			"seed_disk_threshold_mb": schema.Int64Attribute{
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+				Description: "Path seeds larger than this many megabytes are staged in a temporary directory instead of memory. Seeds are always staged in memory when not set",
+			},
+			"commit_signing": schema.SingleNestedAttribute{
+				Optional:    true,
+				Description: "Key that signs every commit the provider creates",
+				Attributes: map[string]schema.Attribute{
+					"key_armored_wo": schema.StringAttribute{
+						Required:    true,
+						Sensitive:   true,
+						Description: "Armored OpenPGP private key, or OpenSSH private key for SSH signatures",
+					},
+					"passphrase_wo": schema.StringAttribute{
+						Optional:    true,
+						Sensitive:   true,
+						Description: "Passphrase of the key",
+					},
+				},
+			},
+		},
 		Blocks: map[string]schema.Block{
 			"bitbucket": schema.SingleNestedBlock{
 				Attributes: map[string]schema.Attribute{
@@ -89,6 +134,25 @@ func (p *BitbucketProvider) Configure(ctx context.Context, request provider.Conf
 		}
 	}
 
+	if config.GitTransport.ValueString() == gitTransportSsh && config.SshPrivateKeyWo.IsNull() {
+		response.Diagnostics.AddAttributeError(
+			path.Root("ssh_private_key_wo"),
+			"Invalid Configuration",
+			"'ssh_private_key_wo' must be set when 'git_transport' is ssh.",
+		)
+		return
+	}
+
+	_, err := newCommitSigner(config)
+	if err != nil {
+		response.Diagnostics.AddAttributeError(
+			path.Root("commit_signing").AtName("key_armored_wo"),
+			"Invalid Configuration",
+			err.Error(),
+		)
+		return
+	}
+
 	var authentication transport.Authentication
 	if !config.Bitbucket.Token.IsNull() {
 		authentication = transport.BearerAuthentication{
@@ -101,13 +165,14 @@ func (p *BitbucketProvider) Configure(ctx context.Context, request provider.Conf
 		}
 	}
 
+	payloadTransport := transport.NewHttpPayloadTransport(config.Bitbucket.EndPoint.ValueString(),
+		authentication,
+	)
+
 	providerData := &BitbucketProviderData{
-		config: config,
-		client: bitbucket.NewBitbucketClient(
-			transport.NewHttpPayloadTransport(config.Bitbucket.EndPoint.ValueString(),
-				authentication,
-			),
-		),
+		config:    config,
+		client:    bitbucket.NewBitbucketClient(payloadTransport),
+		extension: NewClientExtension(payloadTransport),
 	}
 
 	response.DataSourceData = providerData
@@ -128,12 +193,20 @@ func (p *BitbucketProvider) Resources(ctx context.Context) []func() resource.Res
 		NewProjectPermissionsResource,
 		NewProjectBranchRestrictionsResource,
 		NewProjectMergeChecksResource,
+		NewProjectMergeCheckResource,
+		NewProjectRequiredBuildsResource,
 		NewProjectDefaultReviewersResource,
 		NewRepositoryResource,
+		NewRepositoryForkResource,
+		NewRepositoryFileResource,
+		NewRepositoryDirectoryResource,
 		NewRepositoryPermissionsResource,
 		NewRepositoryBranchRestrictionsResource,
 		NewRepositoryMergeChecksResource,
+		NewRepositoryMergeCheckResource,
+		NewRepositoryRequiredBuildsResource,
 		NewRepositoryDefaultReviewersResource,
+		NewReviewerGroupResource,
 	}
 }
 
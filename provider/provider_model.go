@@ -17,12 +17,23 @@ type Author struct {
 	Email types.String `tfsdk:"email"`
 }
 
+type CommitSigning struct {
+	KeyArmoredWo types.String `tfsdk:"key_armored_wo"`
+	PassphraseWo types.String `tfsdk:"passphrase_wo"`
+}
+
 type BitbucketProviderConfig struct {
-	Bitbucket EndPoint `tfsdk:"bitbucket"`
-	Author    Author   `tfsdk:"author"`
+	Bitbucket           EndPoint       `tfsdk:"bitbucket"`
+	Author              Author         `tfsdk:"author"`
+	CommitSigning       *CommitSigning `tfsdk:"commit_signing"`
+	GitTransport        types.String   `tfsdk:"git_transport"`
+	SshPrivateKeyWo     types.String   `tfsdk:"ssh_private_key_wo"`
+	KnownHosts          types.String   `tfsdk:"known_hosts"`
+	SeedDiskThresholdMb types.Int64    `tfsdk:"seed_disk_threshold_mb"`
 }
 
 type BitbucketProviderData struct {
-	config BitbucketProviderConfig
-	client *bitbucket.Client
+	config    BitbucketProviderConfig
+	client    *bitbucket.Client
+	extension *ClientExtension
 }
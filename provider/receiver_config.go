@@ -27,6 +27,9 @@ func ConfigureDataSource(receiver ConfigurableReceiver, ctx context.Context, req
 	}
 
 	receiver.setConfig(data.config, data.client)
+	if extended, ok := receiver.(ExtendedReceiver); ok {
+		extended.setExtension(data.extension)
+	}
 }
 
 func ConfigureResource(receiver ConfigurableReceiver, ctx context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
@@ -44,4 +47,7 @@ func ConfigureResource(receiver ConfigurableReceiver, ctx context.Context, reque
 	}
 
 	receiver.setConfig(data.config, data.client)
+	if extended, ok := receiver.(ExtendedReceiver); ok {
+		extended.setExtension(data.extension)
+	}
 }
@@ -12,4 +12,7 @@ type RepositoryDefaultReviewersModel struct {
 	TargetType types.String `tfsdk:"target_type"`
 	Reviewers  []string     `tfsdk:"reviewers"`
 	Requires   types.Int64  `tfsdk:"requires"`
+
+	ReviewerGroups      []string   `tfsdk:"reviewer_groups"`
+	UnresolvedReviewers types.List `tfsdk:"unresolved_reviewers"`
 }
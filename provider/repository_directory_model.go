@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type RepositoryDirectoryModel struct {
+	ID                    types.String `tfsdk:"id"`
+	Project               types.String `tfsdk:"project"`
+	Repo                  types.String `tfsdk:"repo"`
+	Branch                types.String `tfsdk:"branch"`
+	Source                types.String `tfsdk:"source"`
+	TargetPrefix          types.String `tfsdk:"target_prefix"`
+	CommitMessage         types.String `tfsdk:"commit_message"`
+	Files                 types.Map    `tfsdk:"files"`
+	ContentHash           types.String `tfsdk:"content_hash"`
+	CommitId              types.String `tfsdk:"commit_id"`
+	SigningKeyFingerprint types.String `tfsdk:"signing_key_fingerprint"`
+}
+
+func (m RepositoryDirectoryModel) getFiles(ctx context.Context) (map[string]string, diag.Diagnostics) {
+	files := make(map[string]string)
+	if m.Files.IsNull() || m.Files.IsUnknown() {
+		return files, nil
+	}
+
+	diags := m.Files.ElementsAs(ctx, &files, false)
+	return files, diags
+}
+
+func (m *RepositoryDirectoryModel) setFiles(ctx context.Context, files map[string]string) diag.Diagnostics {
+	value, diags := types.MapValueFrom(ctx, types.StringType, files)
+	if diags.HasError() {
+		return diags
+	}
+
+	m.Files = value
+	m.ContentHash = types.StringValue(treeHash(files))
+	return nil
+}
@@ -0,0 +1,24 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type RepositoryFileModel struct {
+	ID            types.String `tfsdk:"id"`
+	Project       types.String `tfsdk:"project"`
+	Repo          types.String `tfsdk:"repo"`
+	Branch        types.String `tfsdk:"branch"`
+	FilePath      types.String `tfsdk:"file_path"`
+	Content       types.String `tfsdk:"content"`
+	CommitMessage types.String `tfsdk:"commit_message"`
+	ContentSha256 types.String `tfsdk:"content_sha256"`
+	CommitId      types.String `tfsdk:"commit_id"`
+}
+
+func contentSha256(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
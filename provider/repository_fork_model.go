@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yunarta/terraform-provider-commons/util"
+	"strings"
+)
+
+type RepositoryForkModel struct {
+	ID            types.String `tfsdk:"id"`
+	OriginProject types.String `tfsdk:"origin_project"`
+	OriginRepo    types.String `tfsdk:"origin_repo"`
+	Project       types.String `tfsdk:"project"`
+	Name          types.String `tfsdk:"name"`
+	Slug          types.String `tfsdk:"slug"`
+	HttpCloneUrl  types.String `tfsdk:"http_clone_url"`
+	SshCloneUrl   types.String `tfsdk:"ssh_clone_url"`
+	RefSync       types.Bool   `tfsdk:"ref_sync"`
+
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+
+	AssignmentVersion types.String `tfsdk:"assignment_version"`
+	Assignments       types.List   `tfsdk:"assignments"`
+	ComputedUsers     types.List   `tfsdk:"computed_users"`
+	ComputedGroups    types.List   `tfsdk:"computed_groups"`
+}
+
+var _ RepositoryPermissionInterface = &RepositoryForkModel{}
+
+func (m RepositoryForkModel) getProjectKeyAndSlug(ctx context.Context) (projectKey string, slug string) {
+	return m.Project.ValueString(), m.Slug.ValueString()
+}
+
+func (m RepositoryForkModel) getAssignment(ctx context.Context) (Assignments, diag.Diagnostics) {
+	var assignments Assignments = make([]Assignment, 0)
+
+	diags := m.Assignments.ElementsAs(ctx, &assignments, true)
+	return assignments, diags
+}
+
+func NewRepositoryForkModel(repository *RepositoryDetails, refSync bool, plan RepositoryForkModel, assignmentResult *AssignmentResult) *RepositoryForkModel {
+	model := &RepositoryForkModel{
+		ID:                types.StringValue(fmt.Sprintf("%v", repository.ID)),
+		OriginProject:     plan.OriginProject,
+		OriginRepo:        plan.OriginRepo,
+		Project:           keepConfiguredCase(plan.Project, repository.Project.Key),
+		Name:              types.StringValue(repository.Name),
+		Slug:              types.StringValue(repository.Slug),
+		HttpCloneUrl:      util.NullString(repository.HttpCloneUrl()),
+		SshCloneUrl:       util.NullString(repository.SshCloneUrl()),
+		RefSync:           types.BoolValue(refSync),
+		Timeouts:          plan.Timeouts,
+		AssignmentVersion: plan.AssignmentVersion,
+		Assignments:       plan.Assignments,
+		ComputedUsers:     assignmentResult.ComputedUsers,
+		ComputedGroups:    assignmentResult.ComputedGroups,
+	}
+
+	if repository.Origin != nil {
+		model.OriginProject = keepConfiguredCase(plan.OriginProject, repository.Origin.Project.Key)
+		model.OriginRepo = keepConfiguredCase(plan.OriginRepo, repository.Origin.Slug)
+	}
+
+	return model
+}
+
+// keepConfiguredCase keeps the configured value when the server returns the same
+// key or slug in another case.
+func keepConfiguredCase(configured types.String, server string) types.String {
+	if strings.EqualFold(configured.ValueString(), server) {
+		return configured
+	}
+
+	return types.StringValue(server)
+}
@@ -0,0 +1,235 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	cryptossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"os"
+	"strings"
+	"time"
+)
+
+const gitRemoteName = "bitbucket"
+
+const (
+	gitTransportHttp = "http"
+	gitTransportSsh  = "ssh"
+)
+
+// gitEndpoint returns the clone URL of a repository and the credentials of the
+// provider for the configured git_transport.
+func gitEndpoint(config BitbucketProviderConfig, repository *RepositoryDetails) (string, transport.AuthMethod, error) {
+	if config.GitTransport.ValueString() == gitTransportSsh {
+		cloneUrl := repository.SshCloneUrl()
+		if cloneUrl == "" {
+			return "", nil, fmt.Errorf("the server did not return an ssh clone URL for the repository")
+		}
+
+		auth, err := sshAuth(config)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return cloneUrl, auth, nil
+	}
+
+	cloneUrl := repository.HttpCloneUrl()
+	if cloneUrl == "" {
+		return "", nil, fmt.Errorf("the server did not return an http clone URL for the repository")
+	}
+
+	return cloneUrl, httpAuth(config), nil
+}
+
+// httpAuth returns the credentials of the provider for git over http.
+func httpAuth(config BitbucketProviderConfig) transport.AuthMethod {
+	if config.Bitbucket.Token.IsNull() {
+		return &http.BasicAuth{
+			Username: config.Bitbucket.Username.ValueString(),
+			Password: config.Bitbucket.Password.ValueString(),
+		}
+	}
+
+	return &http.BasicAuth{
+		Username: config.Bitbucket.Username.ValueString(),
+		Password: config.Bitbucket.Token.ValueString(),
+	}
+}
+
+// sshAuth returns the ssh_private_key_wo credentials of the provider. Host keys
+// are verified against known_hosts, or the known_hosts files of the user when it
+// is not set.
+func sshAuth(config BitbucketProviderConfig) (transport.AuthMethod, error) {
+	auth, err := ssh.NewPublicKeys("git", []byte(config.SshPrivateKeyWo.ValueString()), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ssh_private_key_wo: %w", err)
+	}
+
+	if config.KnownHosts.IsNull() {
+		auth.HostKeyCallback, err = ssh.NewKnownHostsCallback()
+	} else {
+		auth.HostKeyCallback, err = knownHostsCallback(config.KnownHosts.ValueString())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known hosts: %w", err)
+	}
+
+	return auth, nil
+}
+
+// knownHostsCallback verifies host keys against the content of a known_hosts
+// file.
+func knownHostsCallback(knownHosts string) (cryptossh.HostKeyCallback, error) {
+	file, err := os.CreateTemp("", "known_hosts")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(knownHosts)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return knownhosts.New(file.Name())
+}
+
+// authorSignature returns the provider author, which is used for every commit the
+// provider creates.
+func authorSignature(config BitbucketProviderConfig) *object.Signature {
+	return &object.Signature{
+		Name:  config.Author.Name.ValueString(),
+		Email: config.Author.Email.ValueString(),
+		When:  time.Now(),
+	}
+}
+
+// pushToBitbucket pushes the given refspecs of a local repository to a Bitbucket
+// repository. Every push of the provider goes through here.
+func pushToBitbucket(ctx context.Context, providerConfig BitbucketProviderConfig, repo *git.Repository, repository *RepositoryDetails, refSpecs ...config.RefSpec) error {
+	cloneUrl, auth, err := gitEndpoint(providerConfig, repository)
+	if err != nil {
+		return err
+	}
+
+	remote, err := repo.Remote(gitRemoteName)
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		remote, err = repo.CreateRemote(&config.RemoteConfig{
+			Name: gitRemoteName,
+			URLs: []string{cloneUrl},
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	return remote.PushContext(ctx, &git.PushOptions{
+		RemoteName: gitRemoteName,
+		Auth:       auth,
+		RefSpecs:   refSpecs,
+		Progress:   &progressLogger{ctx: ctx, url: cloneUrl},
+	})
+}
+
+// progressLogger writes the sideband progress of git to tflog, one line at a
+// time.
+type progressLogger struct {
+	ctx     context.Context
+	url     string
+	pending []byte
+}
+
+func (logger *progressLogger) Write(p []byte) (int, error) {
+	logger.pending = append(logger.pending, p...)
+	for {
+		index := bytes.IndexAny(logger.pending, "\r\n")
+		if index < 0 {
+			break
+		}
+
+		line := strings.TrimSpace(string(logger.pending[:index]))
+		logger.pending = logger.pending[index+1:]
+		if line != "" {
+			tflog.Debug(logger.ctx, line, map[string]any{
+				"url": logger.url,
+			})
+		}
+	}
+
+	return len(p), nil
+}
+
+// cloneFromBitbucket clones a Bitbucket repository with the URL and credentials
+// of the configured git_transport.
+func cloneFromBitbucket(ctx context.Context, providerConfig BitbucketProviderConfig, repository *RepositoryDetails, worktree billy.Filesystem, options *git.CloneOptions) (*git.Repository, error) {
+	cloneUrl, auth, err := gitEndpoint(providerConfig, repository)
+	if err != nil {
+		return nil, err
+	}
+
+	options.URL = cloneUrl
+	options.Auth = auth
+
+	return git.CloneContext(ctx, memory.NewStorage(), worktree, options)
+}
+
+// cloneBranch clones the last commit of a branch into memory, with a worktree
+// that can be committed and pushed back with pushToBitbucket.
+func cloneBranch(ctx context.Context, providerConfig BitbucketProviderConfig, repository *RepositoryDetails, branch string) (*git.Repository, error) {
+	return cloneFromBitbucket(ctx, providerConfig, repository, memfs.New(), &git.CloneOptions{
+		RemoteName:    gitRemoteName,
+		ReferenceName: plumbing.NewBranchReferenceName(branch),
+		SingleBranch:  true,
+		Depth:         1,
+		Tags:          git.NoTags,
+	})
+}
+
+// branchRefSpec returns a refspec that pushes a local branch to the branch with
+// the same name.
+func branchRefSpec(branch string) config.RefSpec {
+	ref := branchRefId(branch)
+	return config.RefSpec(fmt.Sprintf("%s:%s", ref, ref))
+}
+
+// writeWorktreeFile writes content to a file of the worktree and stages it.
+func writeWorktreeFile(worktree *git.Worktree, filePath string, content string) error {
+	file, err := worktree.Filesystem.Create(filePath)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write([]byte(content))
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	_, err = worktree.Add(filePath)
+	return err
+}
@@ -8,4 +8,8 @@ type RepositoryMergeChecksModel struct {
 	AllReviewerApproval    types.Bool  `tfsdk:"all_reviewer_approval"`
 	MinimumApproval        types.Int64 `tfsdk:"minimum_approvals"`
 	MinimumSuccessfulBuild types.Int64 `tfsdk:"minimum_successful_builds"`
+
+	AllReviewerApprovalMode    types.String `tfsdk:"all_reviewer_approval_mode"`
+	MinimumApprovalMode        types.String `tfsdk:"minimum_approvals_mode"`
+	MinimumSuccessfulBuildMode types.String `tfsdk:"minimum_successful_builds_mode"`
 }
@@ -5,19 +5,62 @@ import (
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/yunarta/terraform-atlassian-api-client/bitbucket"
+	"github.com/yunarta/terraform-provider-commons/util"
 )
 
+const (
+	deleteModeRetain  = "retain"
+	deleteModeArchive = "archive"
+	deleteModeRename  = "rename"
+	deleteModeDelete  = "delete"
+)
+
+var deleteModes = []string{deleteModeRetain, deleteModeArchive, deleteModeRename, deleteModeDelete}
+
+type RepositoryTemplateModel struct {
+	Project types.String `tfsdk:"project"`
+	Repo    types.String `tfsdk:"repo"`
+	Ref     types.String `tfsdk:"ref"`
+	Squash  types.Bool   `tfsdk:"squash"`
+}
+
+type RepositoryImportModel struct {
+	Url        types.String `tfsdk:"url"`
+	Username   types.String `tfsdk:"username"`
+	PasswordWo types.String `tfsdk:"password_wo"`
+}
+
 type RepositoryModel struct {
-	ID              types.String `tfsdk:"id"`
-	RetainOnDelete  types.Bool   `tfsdk:"retain_on_delete"`
-	ArchiveOnDelete types.Bool   `tfsdk:"archive_on_delete"`
-	Project         types.String `tfsdk:"project"`
-	Slug            types.String `tfsdk:"slug"`
-	Name            types.String `tfsdk:"name"`
-	Description     types.String `tfsdk:"description"`
-	Readme          types.String `tfsdk:"readme"`
-	Path            types.String `tfsdk:"path"`
+	ID                    types.String             `tfsdk:"id"`
+	RetainOnDelete        types.Bool               `tfsdk:"retain_on_delete"`
+	ArchiveOnDelete       types.Bool               `tfsdk:"archive_on_delete"`
+	AllowProjectMove      types.Bool               `tfsdk:"allow_project_move"`
+	DeleteMode            types.String             `tfsdk:"delete_mode"`
+	Archived              types.Bool               `tfsdk:"archived"`
+	Project               types.String             `tfsdk:"project"`
+	Slug                  types.String             `tfsdk:"slug"`
+	Name                  types.String             `tfsdk:"name"`
+	Description           types.String             `tfsdk:"description"`
+	Forkable              types.Bool               `tfsdk:"forkable"`
+	HttpCloneUrl          types.String             `tfsdk:"http_clone_url"`
+	SshCloneUrl           types.String             `tfsdk:"ssh_clone_url"`
+	BrowseUrl             types.String             `tfsdk:"browse_url"`
+	ScmId                 types.String             `tfsdk:"scm_id"`
+	State                 types.String             `tfsdk:"state"`
+	Public                types.Bool               `tfsdk:"public"`
+	DefaultBranch         types.String             `tfsdk:"default_branch"`
+	Readme                types.String             `tfsdk:"readme"`
+	Path                  types.String             `tfsdk:"path"`
+	Exclude               []string                 `tfsdk:"exclude"`
+	SeedVariables         map[string]string        `tfsdk:"seed_variables"`
+	InitialBranches       []string                 `tfsdk:"initial_branches"`
+	InitialTags           []string                 `tfsdk:"initial_tags"`
+	Template              *RepositoryTemplateModel `tfsdk:"template"`
+	ImportFrom            *RepositoryImportModel   `tfsdk:"import_from"`
+	ImportedRefs          types.List               `tfsdk:"imported_refs"`
+	PathDigest            types.String             `tfsdk:"path_digest"`
+	Initialized           types.Bool               `tfsdk:"initialized"`
+	SigningKeyFingerprint types.String             `tfsdk:"signing_key_fingerprint"`
 
 	AssignmentVersion types.String `tfsdk:"assignment_version"`
 	Assignments       types.List   `tfsdk:"assignments"`
@@ -31,6 +74,22 @@ func (m RepositoryModel) getProjectKeyAndSlug(ctx context.Context) (projectKey s
 	return m.Project.ValueString(), m.Slug.ValueString()
 }
 
+// getDeleteMode returns delete_mode, or the mode implied by the deprecated
+// retain_on_delete and archive_on_delete attributes when it is not set.
+func (m RepositoryModel) getDeleteMode() string {
+	if !m.DeleteMode.IsNull() {
+		return m.DeleteMode.ValueString()
+	}
+
+	if m.RetainOnDelete.ValueBool() {
+		return deleteModeRetain
+	} else if m.ArchiveOnDelete.ValueBool() {
+		return deleteModeArchive
+	}
+
+	return deleteModeDelete
+}
+
 func (m RepositoryModel) getAssignment(ctx context.Context) (Assignments, diag.Diagnostics) {
 	var assignments Assignments = make([]Assignment, 0)
 
@@ -38,20 +97,78 @@ func (m RepositoryModel) getAssignment(ctx context.Context) (Assignments, diag.D
 	return assignments, diags
 }
 
-func NewRepositoryModel(repository *bitbucket.Repository, plan RepositoryModel, assignmentResult *AssignmentResult) *RepositoryModel {
+func NewRepositoryModel(repository *RepositoryDetails, plan RepositoryModel, assignmentResult *AssignmentResult) *RepositoryModel {
+	// the server drops an empty description, which is kept as configured
+	description := util.NullString(repository.Description)
+	if repository.Description == "" && plan.Description.ValueString() == "" && !plan.Description.IsUnknown() {
+		description = plan.Description
+	}
+
 	return &RepositoryModel{
-		ID:                types.StringValue(fmt.Sprintf("%v", repository.ID)),
-		Slug:              types.StringValue(repository.Slug),
-		Name:              types.StringValue(repository.Name),
-		Description:       plan.Description,
-		Project:           plan.Project,
-		RetainOnDelete:    plan.RetainOnDelete,
-		ArchiveOnDelete:   plan.ArchiveOnDelete,
-		Readme:            plan.Readme,
-		Path:              plan.Path,
-		AssignmentVersion: plan.AssignmentVersion,
-		Assignments:       plan.Assignments,
-		ComputedUsers:     assignmentResult.ComputedUsers,
-		ComputedGroups:    assignmentResult.ComputedGroups,
+		ID:                    types.StringValue(fmt.Sprintf("%v", repository.ID)),
+		Slug:                  types.StringValue(repository.Slug),
+		Name:                  types.StringValue(repository.Name),
+		Description:           description,
+		Forkable:              types.BoolValue(repository.Forkable),
+		Public:                types.BoolValue(repository.Public),
+		HttpCloneUrl:          util.NullString(repository.HttpCloneUrl()),
+		SshCloneUrl:           util.NullString(repository.SshCloneUrl()),
+		BrowseUrl:             util.NullString(repository.BrowseUrl()),
+		ScmId:                 types.StringValue(repository.ScmId),
+		State:                 types.StringValue(repository.State),
+		Project:               plan.Project,
+		RetainOnDelete:        plan.RetainOnDelete,
+		ArchiveOnDelete:       plan.ArchiveOnDelete,
+		AllowProjectMove:      plan.AllowProjectMove,
+		DeleteMode:            plan.DeleteMode,
+		Archived:              plan.Archived,
+		DefaultBranch:         plan.DefaultBranch,
+		Readme:                plan.Readme,
+		Path:                  plan.Path,
+		Exclude:               plan.Exclude,
+		SeedVariables:         plan.SeedVariables,
+		InitialBranches:       plan.InitialBranches,
+		InitialTags:           plan.InitialTags,
+		Template:              plan.Template,
+		ImportFrom:            plan.ImportFrom,
+		ImportedRefs:          plan.ImportedRefs,
+		PathDigest:            plan.PathDigest,
+		Initialized:           plan.Initialized,
+		SigningKeyFingerprint: plan.SigningKeyFingerprint,
+		AssignmentVersion:     plan.AssignmentVersion,
+		Assignments:           plan.Assignments,
+		ComputedUsers:         assignmentResult.ComputedUsers,
+		ComputedGroups:        assignmentResult.ComputedGroups,
+	}
+}
+
+// resolvePathDigest returns the path digest of the plan, computing it when path
+// was not known while planning.
+func (m RepositoryModel) resolvePathDigest() types.String {
+	if !m.PathDigest.IsUnknown() {
+		return m.PathDigest
 	}
+
+	if m.Path.IsNull() || m.Path.IsUnknown() {
+		return types.StringNull()
+	}
+
+	digest, err := seedPathDigest(m.Path.ValueString(), m.Exclude)
+	if err != nil {
+		// reported by the initialization
+		return types.StringNull()
+	}
+
+	return types.StringValue(digest)
+}
+
+// boolPointer returns nil for null or unknown values, which leaves the setting to
+// the server.
+func boolPointer(value types.Bool) *bool {
+	if value.IsNull() || value.IsUnknown() {
+		return nil
+	}
+
+	result := value.ValueBool()
+	return &result
 }
@@ -0,0 +1,515 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// seedTemplateData returns the values available to seed templates. The values of
+// seed_variables are available by name, next to ProjectKey, Slug, Name,
+// CloneURL, SshCloneURL, BrowseURL and DefaultBranch, which take precedence.
+func seedTemplateData(repository *RepositoryDetails, variables map[string]string, branch string) map[string]any {
+	data := make(map[string]any)
+	for name, value := range variables {
+		data[name] = value
+	}
+
+	data["ProjectKey"] = repository.Project.Key
+	data["Slug"] = repository.Slug
+	data["Name"] = repository.Name
+	data["CloneURL"] = repository.HttpCloneUrl()
+	data["SshCloneURL"] = repository.SshCloneUrl()
+	data["BrowseURL"] = repository.BrowseUrl()
+	data["DefaultBranch"] = branch
+
+	return data
+}
+
+// renderSeed executes content as a text/template, failing on values that are not
+// defined.
+func renderSeed(name string, content string, seedData map[string]any) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	var builder strings.Builder
+	err = tmpl.Execute(&builder, seedData)
+	if err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+
+	return builder.String(), nil
+}
+
+// seedTemplateSuffix marks the files under path that are rendered as templates
+// when seed_variables is set. The suffix is stripped from the committed file.
+const seedTemplateSuffix = ".tmpl"
+
+// renderToWorktree renders a local template file into the worktree.
+func renderToWorktree(filesystem billy.Filesystem, file string, info os.FileInfo, destPath string, seedData map[string]any) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	rendered, err := renderSeed(destPath, string(content), seedData)
+	if err != nil {
+		return err
+	}
+
+	destFile, err := filesystem.OpenFile(destPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	_, err = destFile.Write([]byte(rendered))
+	if err != nil {
+		destFile.Close()
+		return err
+	}
+
+	return destFile.Close()
+}
+
+// seedPathDigest checks that source is a directory with files to commit, and
+// returns the combined hash of those files.
+func seedPathDigest(source string, exclude []string) (string, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return "", err
+	}
+
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", source)
+	}
+
+	filter, err := newLocalTreeFilter(source, exclude)
+	if err != nil {
+		return "", err
+	}
+
+	files, err := hashLocalTree(source, filter, "")
+	if err != nil {
+		return "", err
+	}
+
+	if len(files) == 0 {
+		return "", fmt.Errorf("%s has no files to initialize the repository with", source)
+	}
+
+	return treeHash(files), nil
+}
+
+// initializeRepository commits readme, path or template to a new repository.
+// Repositories created with import_from are initialized by the import.
+func (receiver *RepositoryResource) initializeRepository(ctx context.Context, repository *RepositoryDetails, plan RepositoryModel, defaultBranch string) error {
+	if plan.ImportFrom != nil {
+		// imported before the assignments were applied
+		return nil
+	}
+
+	if plan.Readme.IsNull() && plan.Path.IsNull() && plan.Template == nil {
+		return nil
+	}
+
+	var err error
+
+	// every kind of seed commits to the same branch, which is the default branch
+	// of the server when default_branch is not set
+	if defaultBranch == "" {
+		defaultBranch, err = receiver.extension.GetDefaultBranch(plan.Project.ValueString(), repository.Slug)
+		if err != nil {
+			return err
+		}
+
+		if defaultBranch == "" {
+			defaultBranch = plumbing.Master.Short()
+		}
+	}
+
+	// a retried initialization resumes after a seed commit that already landed
+	head, err := receiver.extension.GetBranchHead(plan.Project.ValueString(), repository.Slug, defaultBranch)
+	if err != nil {
+		return err
+	}
+
+	if head != nil {
+		return receiver.createInitialRefs(plan, repository.Slug, head.Id)
+	}
+
+	var seedData map[string]any
+	if plan.SeedVariables != nil {
+		seedData = seedTemplateData(repository, plan.SeedVariables, defaultBranch)
+	}
+
+	switch {
+	case !plan.Readme.IsNull():
+		return receiver.initializeFromReadme(ctx, repository, plan, seedData, defaultBranch)
+	case !plan.Path.IsNull():
+		return receiver.initializeFromPath(ctx, repository, plan, seedData, defaultBranch)
+	case plan.Template != nil:
+		return receiver.initializeFromTemplate(ctx, repository, plan, defaultBranch)
+	}
+
+	return nil
+}
+
+// initializeFromReadme commits readme as README.md through the REST API, or
+// through git when commit_signing is configured, as the REST API can not sign
+// commits.
+func (receiver *RepositoryResource) initializeFromReadme(ctx context.Context, repository *RepositoryDetails, plan RepositoryModel, seedData map[string]any, branch string) error {
+	readme := plan.Readme.ValueString()
+	if seedData != nil {
+		var err error
+		readme, err = renderSeed("readme", readme, seedData)
+		if err != nil {
+			return err
+		}
+	}
+
+	signer, err := newCommitSigner(receiver.config)
+	if err != nil {
+		return err
+	}
+
+	if signer != nil {
+		return receiver.initializeFromSignedReadme(ctx, repository, plan, readme, branch)
+	}
+
+	commit, err := receiver.extension.EditFile(plan.Project.ValueString(), repository.Slug, EditFile{
+		Branch:  branch,
+		Path:    "README.md",
+		Content: readme,
+		Message: "Initial commit",
+	})
+	if err != nil {
+		return err
+	}
+
+	return receiver.createInitialRefs(plan, repository.Slug, commit.Id)
+}
+
+func (receiver *RepositoryResource) initializeFromSignedReadme(ctx context.Context, repository *RepositoryDetails, plan RepositoryModel, readme string, branch string) error {
+	repo, err := git.InitWithOptions(memory.NewStorage(), memfs.New(), git.InitOptions{
+		DefaultBranch: plumbing.NewBranchReferenceName(branch),
+	})
+	if err != nil {
+		return err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	err = writeWorktreeFile(worktree, "README.md", readme)
+	if err != nil {
+		return err
+	}
+
+	options, err := commitOptions(receiver.config)
+	if err != nil {
+		return err
+	}
+
+	hash, err := worktree.Commit("Initial commit", options)
+	if err != nil {
+		return err
+	}
+
+	refSpecs, err := seedRefSpecs(repo, hash, branch, plan)
+	if err != nil {
+		return err
+	}
+
+	return pushToBitbucket(ctx, receiver.config, repo, repository, refSpecs...)
+}
+
+// newSeedStorage returns the storage of a seed repository. Seeds larger than
+// seed_disk_threshold_mb are staged in a temporary directory, which cleanup
+// removes, instead of memory. A negative size stands for a seed whose size is
+// not known in advance, such as an import, which is staged on disk whenever a
+// threshold is set.
+func newSeedStorage(ctx context.Context, config BitbucketProviderConfig, size int64) (storage.Storer, billy.Filesystem, func(), error) {
+	if config.SeedDiskThresholdMb.IsNull() || (size >= 0 && size <= config.SeedDiskThresholdMb.ValueInt64()*1024*1024) {
+		return memory.NewStorage(), memfs.New(), func() {}, nil
+	}
+
+	dir, err := os.MkdirTemp("", "terraform-bitbucket-seed-")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	tflog.Info(ctx, "Staging seed on disk", map[string]any{
+		"size": size,
+		"dir":  dir,
+	})
+
+	cleanup := func() {
+		err := os.RemoveAll(dir)
+		if err != nil {
+			tflog.Warn(ctx, "Failed to remove seed directory", map[string]any{
+				"dir":   dir,
+				"error": err.Error(),
+			})
+		}
+	}
+
+	storer := filesystem.NewStorage(osfs.New(filepath.Join(dir, git.GitDirName)), cache.NewObjectLRUDefault())
+	return storer, osfs.New(filepath.Join(dir, "worktree")), cleanup, nil
+}
+
+// initializeFromPath commits the content of a local directory and pushes it to
+// the branch of the new repository.
+func (receiver *RepositoryResource) initializeFromPath(ctx context.Context, repository *RepositoryDetails, plan RepositoryModel, seedData map[string]any, branch string) error {
+	source := plan.Path.ValueString()
+	filter, err := newLocalTreeFilter(source, plan.Exclude)
+	if err != nil {
+		return err
+	}
+
+	initOptions := git.InitOptions{
+		DefaultBranch: plumbing.NewBranchReferenceName(branch),
+	}
+
+	size, err := localTreeSize(source, filter)
+	if err != nil {
+		return err
+	}
+
+	storer, worktreeFs, cleanup, err := newSeedStorage(ctx, receiver.config, size)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	repo, err := git.InitWithOptions(storer, worktreeFs, initOptions)
+	if err != nil {
+		return err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	// copy filesystem to worktree recursively
+	err = walkLocalTree(source, filter, func(relativePath string, file string, info os.FileInfo) error {
+		if seedData == nil || !info.Mode().IsRegular() || !strings.HasSuffix(relativePath, seedTemplateSuffix) {
+			return copyToWorktree(worktree.Filesystem, file, info, relativePath)
+		}
+
+		return renderToWorktree(worktree.Filesystem, file, info, strings.TrimSuffix(relativePath, seedTemplateSuffix), seedData)
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = worktree.Add(".")
+	if err != nil {
+		return err
+	}
+
+	options, err := commitOptions(receiver.config)
+	if err != nil {
+		return err
+	}
+
+	_, err = worktree.Commit("Initial Commit", options)
+	if err != nil {
+		return err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+
+	refSpecs, err := seedRefSpecs(repo, head.Hash(), head.Name().Short(), plan)
+	if err != nil {
+		return err
+	}
+
+	return pushToBitbucket(ctx, receiver.config, repo, repository, refSpecs...)
+}
+
+// seedRefSpecs points the seed branch, initial_branches and initial_tags at the
+// seed commit, and returns the refspecs that push all of them at once.
+func seedRefSpecs(repo *git.Repository, hash plumbing.Hash, branch string, plan RepositoryModel) ([]config.RefSpec, error) {
+	references := []plumbing.ReferenceName{plumbing.NewBranchReferenceName(branch)}
+	for _, name := range plan.InitialBranches {
+		if name != branch {
+			references = append(references, plumbing.NewBranchReferenceName(name))
+		}
+	}
+	for _, name := range plan.InitialTags {
+		references = append(references, plumbing.NewTagReferenceName(name))
+	}
+
+	refSpecs := make([]config.RefSpec, 0, len(references))
+	for _, reference := range references {
+		err := repo.Storer.SetReference(plumbing.NewHashReference(reference, hash))
+		if err != nil {
+			return nil, err
+		}
+
+		refSpecs = append(refSpecs, config.RefSpec(fmt.Sprintf("%s:%s", reference, reference)))
+	}
+
+	return refSpecs, nil
+}
+
+// templateReferenceName accepts a branch name or a fully qualified ref.
+func templateReferenceName(ref string) plumbing.ReferenceName {
+	if strings.HasPrefix(ref, "refs/") {
+		return plumbing.ReferenceName(ref)
+	}
+
+	return plumbing.NewBranchReferenceName(ref)
+}
+
+// initializeFromTemplate clones the template repository into memory and pushes
+// its history, or a single squashed commit of its tree, to the branch of the new
+// repository.
+func (receiver *RepositoryResource) initializeFromTemplate(ctx context.Context, repository *RepositoryDetails, plan RepositoryModel, branch string) error {
+	template := *plan.Template
+
+	templateRepository, err := receiver.extension.ReadRepository(template.Project.ValueString(), template.Repo.ValueString())
+	if err != nil {
+		return err
+	}
+
+	squash := template.Squash.ValueBool()
+	cloneOptions := &git.CloneOptions{
+		SingleBranch: true,
+		Tags:         git.NoTags,
+	}
+	if !template.Ref.IsNull() {
+		cloneOptions.ReferenceName = templateReferenceName(template.Ref.ValueString())
+	}
+	if squash {
+		// only the tree of the last commit is needed
+		cloneOptions.Depth = 1
+	}
+
+	repo, err := cloneFromBitbucket(ctx, receiver.config, templateRepository, nil, cloneOptions)
+	if err != nil {
+		return fmt.Errorf("failed to clone template %s/%s: %w", template.Project.ValueString(), template.Repo.ValueString(), err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+
+	hash := head.Hash()
+	if squash {
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			return err
+		}
+
+		signature := authorSignature(receiver.config)
+		squashed := &object.Commit{
+			Author:    *signature,
+			Committer: *signature,
+			Message:   "Initial Commit",
+			TreeHash:  commit.TreeHash,
+		}
+
+		hash, err = storeCommit(receiver.config, repo, squashed)
+		if err != nil {
+			return err
+		}
+	}
+
+	refSpecs, err := seedRefSpecs(repo, hash, branch, plan)
+	if err != nil {
+		return err
+	}
+
+	return pushToBitbucket(ctx, receiver.config, repo, repository, refSpecs...)
+}
+
+// importRepository mirrors every branch and tag of an external repository into
+// the new repository, and returns the imported refs with the default branch of
+// the source.
+func (receiver *RepositoryResource) importRepository(ctx context.Context, repository *RepositoryDetails, importFrom RepositoryImportModel, password string) ([]string, string, error) {
+	cloneOptions := &git.CloneOptions{
+		URL:    importFrom.Url.ValueString(),
+		Mirror: true,
+	}
+	if !importFrom.Username.IsNull() || password != "" {
+		cloneOptions.Auth = &http.BasicAuth{
+			Username: importFrom.Username.ValueString(),
+			Password: password,
+		}
+	}
+
+	storer, _, cleanup, err := newSeedStorage(ctx, receiver.config, -1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cleanup()
+
+	repo, err := git.CloneContext(ctx, storer, nil, cloneOptions)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to clone %s: %w", importFrom.Url.ValueString(), err)
+	}
+
+	references, err := repo.References()
+	if err != nil {
+		return nil, "", err
+	}
+
+	refs := make([]string, 0)
+	err = references.ForEach(func(reference *plumbing.Reference) error {
+		if reference.Name().IsBranch() || reference.Name().IsTag() {
+			refs = append(refs, reference.Name().String())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(refs) == 0 {
+		return nil, "", fmt.Errorf("%s has no branches or tags to import", importFrom.Url.ValueString())
+	}
+	sort.Strings(refs)
+
+	defaultBranch := ""
+	head, err := repo.Reference(plumbing.HEAD, false)
+	if err == nil && head.Type() == plumbing.SymbolicReference && head.Target().IsBranch() {
+		defaultBranch = head.Target().Short()
+	}
+
+	err = pushToBitbucket(ctx, receiver.config, repo, repository,
+		"+refs/heads/*:refs/heads/*",
+		"+refs/tags/*:refs/tags/*",
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to push the refs of %s: %w", importFrom.Url.ValueString(), err)
+	}
+
+	return refs, defaultBranch, nil
+}
@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"sort"
+	"strings"
+)
+
+var refMatcherTypes = []string{"any", "branch", "pattern", "model_branch", "model_category"}
+var refMatcherTypesMap = map[string]string{
+	"any":            "ANY_REF",
+	"branch":         "BRANCH",
+	"pattern":        "PATTERN",
+	"model_branch":   "MODEL_BRANCH",
+	"model_category": "MODEL_CATEGORY",
+}
+var refMatcherTypesReverseMap = map[string]string{
+	"ANY_REF":        "any",
+	"BRANCH":         "branch",
+	"PATTERN":        "pattern",
+	"MODEL_BRANCH":   "model_branch",
+	"MODEL_CATEGORY": "model_category",
+}
+
+type RefMatcherModel struct {
+	Type types.String `tfsdk:"type"`
+	Id   types.String `tfsdk:"id"`
+}
+
+type ProjectRequiredBuildsModel struct {
+	Id        types.Int64      `tfsdk:"id"`
+	Project   types.String     `tfsdk:"project"`
+	BuildKeys []string         `tfsdk:"build_keys"`
+	Target    RefMatcherModel  `tfsdk:"target"`
+	Exempt    *RefMatcherModel `tfsdk:"exempt"`
+}
+
+type RepositoryRequiredBuildsModel struct {
+	Id        types.Int64      `tfsdk:"id"`
+	Project   types.String     `tfsdk:"project"`
+	Repo      types.String     `tfsdk:"repo"`
+	BuildKeys []string         `tfsdk:"build_keys"`
+	Target    RefMatcherModel  `tfsdk:"target"`
+	Exempt    *RefMatcherModel `tfsdk:"exempt"`
+}
+
+func refMatcherAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"type": schema.StringAttribute{
+			Required: true,
+			Validators: []validator.String{
+				stringvalidator.OneOf(refMatcherTypes...),
+			},
+		},
+		"id": schema.StringAttribute{
+			Optional:    true,
+			Description: "Branch name, pattern or branching model id; not used by the any type",
+		},
+	}
+}
+
+// refMatcherIdValidator requires an id for every matcher type except any, which
+// would otherwise match refs/heads/ or an empty pattern.
+type refMatcherIdValidator struct {
+}
+
+func (v refMatcherIdValidator) Description(ctx context.Context) string {
+	return "id must be set unless type is any"
+}
+
+func (v refMatcherIdValidator) MarkdownDescription(ctx context.Context) string {
+	return "`id` must be set unless `type` is `any`"
+}
+
+func (v refMatcherIdValidator) ValidateObject(ctx context.Context, request validator.ObjectRequest, response *validator.ObjectResponse) {
+	if request.ConfigValue.IsNull() || request.ConfigValue.IsUnknown() {
+		return
+	}
+
+	var matcher RefMatcherModel
+	diags := request.ConfigValue.As(ctx, &matcher, basetypes.ObjectAsOptions{})
+	if diags.HasError() {
+		response.Diagnostics.Append(diags...)
+		return
+	}
+
+	if matcher.Type.IsUnknown() || matcher.Type.ValueString() == "any" {
+		return
+	}
+
+	if matcher.Id.IsNull() || (!matcher.Id.IsUnknown() && matcher.Id.ValueString() == "") {
+		response.Diagnostics.AddAttributeError(request.Path.AtName("id"),
+			"Invalid Configuration",
+			fmt.Sprintf("'id' must be set when 'type' is %s.", matcher.Type.ValueString()),
+		)
+	}
+}
+
+// branchRefId expands a plain branch name into the fully qualified ref that
+// Bitbucket stores for branch matchers.
+func branchRefId(id string) string {
+	if strings.HasPrefix(id, "refs/") {
+		return id
+	}
+
+	return "refs/heads/" + id
+}
+
+func (m RefMatcherModel) toRefMatcher() RefMatcher {
+	matcherType := refMatcherTypesMap[m.Type.ValueString()]
+	id := m.Id.ValueString()
+	switch matcherType {
+	case "BRANCH":
+		id = branchRefId(id)
+	case "ANY_REF":
+		id = "ANY_REF_MATCHER_ID"
+	}
+
+	return RefMatcher{
+		Id: id,
+		Type: RefMatcherType{
+			Id: matcherType,
+		},
+	}
+}
+
+// newRefMatcherModel converts the server matcher, keeping the id in state when
+// it refers to the same ref, such as main and refs/heads/main.
+func newRefMatcherModel(matcher RefMatcher, inState *RefMatcherModel) RefMatcherModel {
+	model := RefMatcherModel{
+		Type: types.StringValue(refMatcherTypesReverseMap[matcher.Type.Id]),
+		Id:   types.StringValue(matcher.Id),
+	}
+
+	if inState != nil && inState.Type.Equal(model.Type) {
+		if matcher.Type.Id == "ANY_REF" || inState.toRefMatcher().Id == matcher.Id {
+			model.Id = inState.Id
+		}
+	} else if matcher.Type.Id == "ANY_REF" {
+		model.Id = types.StringNull()
+	}
+
+	return model
+}
+
+func newRequiredBuildCondition(buildKeys []string, target RefMatcherModel, exempt *RefMatcherModel) RequiredBuildCondition {
+	condition := RequiredBuildCondition{
+		BuildParentKeys: buildKeys,
+		RefMatcher:      target.toRefMatcher(),
+	}
+
+	if exempt != nil {
+		exemptRefMatcher := exempt.toRefMatcher()
+		condition.ExemptRefMatcher = &exemptRefMatcher
+	}
+
+	return condition
+}
+
+// readRequiredBuildCondition returns the build keys, target and exempt matchers
+// of the condition, sorted and normalized against the values in state.
+func readRequiredBuildCondition(condition *RequiredBuildCondition, buildKeys []string, target RefMatcherModel, exempt *RefMatcherModel) ([]string, RefMatcherModel, *RefMatcherModel) {
+	keys := append([]string{}, condition.BuildParentKeys...)
+	sort.Strings(keys)
+
+	inStateKeys := append([]string{}, buildKeys...)
+	sort.Strings(inStateKeys)
+	if strings.Join(keys, "\n") == strings.Join(inStateKeys, "\n") {
+		keys = buildKeys
+	}
+
+	var exemptModel *RefMatcherModel
+	if condition.ExemptRefMatcher != nil {
+		readExempt := newRefMatcherModel(*condition.ExemptRefMatcher, exempt)
+		exemptModel = &readExempt
+	}
+
+	return keys, newRefMatcherModel(condition.RefMatcher, &target), exemptModel
+}
@@ -2,29 +2,22 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"github.com/go-git/go-billy/v5/memfs"
-	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/config"
-	"github.com/go-git/go-git/v5/plumbing/object"
-	"github.com/go-git/go-git/v5/plumbing/transport"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
-	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/yunarta/terraform-atlassian-api-client/bitbucket"
 	"github.com/yunarta/terraform-provider-commons/util"
-	"io"
-	"os"
-	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -32,11 +25,14 @@ import (
 )
 
 var (
-	_ resource.Resource                = &RepositoryResource{}
-	_ resource.ResourceWithConfigure   = &RepositoryResource{}
-	_ resource.ResourceWithImportState = &RepositoryResource{}
-	_ RepositoryPermissionReceiver     = &RepositoryResource{}
-	_ ConfigurableReceiver             = &RepositoryResource{}
+	_ resource.Resource                   = &RepositoryResource{}
+	_ resource.ResourceWithConfigure      = &RepositoryResource{}
+	_ resource.ResourceWithImportState    = &RepositoryResource{}
+	_ resource.ResourceWithValidateConfig = &RepositoryResource{}
+	_ resource.ResourceWithModifyPlan     = &RepositoryResource{}
+	_ RepositoryPermissionReceiver        = &RepositoryResource{}
+	_ ConfigurableReceiver                = &RepositoryResource{}
+	_ ExtendedReceiver                    = &RepositoryResource{}
 )
 
 func NewRepositoryResource() resource.Resource {
@@ -44,8 +40,9 @@ func NewRepositoryResource() resource.Resource {
 }
 
 type RepositoryResource struct {
-	config BitbucketProviderConfig
-	client *bitbucket.Client
+	config    BitbucketProviderConfig
+	client    *bitbucket.Client
+	extension *ClientExtension
 }
 
 func (receiver *RepositoryResource) getClient() *bitbucket.Client {
@@ -57,6 +54,10 @@ func (receiver *RepositoryResource) setConfig(config BitbucketProviderConfig, cl
 	receiver.client = client
 }
 
+func (receiver *RepositoryResource) setExtension(extension *ClientExtension) {
+	receiver.extension = extension
+}
+
 func (receiver *RepositoryResource) Metadata(ctx context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
 	response.TypeName = request.ProviderTypeName + "_repository"
 }
@@ -65,45 +66,117 @@ type createSlug struct {
 }
 
 func (r createSlug) Description(ctx context.Context) string {
-	return "If the value of this attribute changes, Terraform will destroy and recreate the resource."
+	return "The slug is derived from the repository name, and changes when the repository is renamed."
 }
 
 func (r createSlug) MarkdownDescription(ctx context.Context) string {
-	return "If the value of this attribute changes, Terraform will destroy and recreate the resource."
+	return "The slug is derived from the repository name, and changes when the repository is renamed."
 }
 
 func (r createSlug) PlanModifyString(ctx context.Context, request planmodifier.StringRequest, response *planmodifier.StringResponse) {
-	var repoName types.String
+	var repoName, inStateName types.String
 	request.Plan.GetAttribute(ctx, path.Root("name"), &repoName)
+	request.State.GetAttribute(ctx, path.Root("name"), &inStateName)
 
-	// Convert the string to lowercase
-	slug := strings.ToLower(repoName.ValueString())
+	// Keep the slug that Bitbucket assigned as long as the repository is not renamed
+	if !request.StateValue.IsNull() && repoName.Equal(inStateName) {
+		response.PlanValue = request.StateValue
+		return
+	}
 
-	// Reduce consecutive spaces to a single space
-	slug = regexp.MustCompile("\\s+").ReplaceAllString(slug, " ")
+	// Bitbucket derives the slug of a new or renamed repository, which is only
+	// known after apply
+	response.PlanValue = types.StringUnknown()
+}
 
-	// Replace spaces with a single hyphen
-	slug = strings.ReplaceAll(slug, " ", "-")
+// useStateUnlessMoved keeps values that depend on the repository location, such as
+// clone URLs, as long as the repository is neither renamed nor moved.
+type useStateUnlessMoved struct {
+}
 
-	// Remove any characters that are not alphanumeric, hyphens, underscores, or dots
-	reg := regexp.MustCompile("[^a-z0-9-_.]")
-	slug = reg.ReplaceAllString(slug, "")
+func (r useStateUnlessMoved) Description(ctx context.Context) string {
+	return "The value only changes when the repository is renamed or moved to another project."
+}
 
-	response.PlanValue = types.StringValue(slug)
+func (r useStateUnlessMoved) MarkdownDescription(ctx context.Context) string {
+	return "The value only changes when the repository is renamed or moved to another project."
+}
+
+func (r useStateUnlessMoved) PlanModifyString(ctx context.Context, request planmodifier.StringRequest, response *planmodifier.StringResponse) {
+	if request.StateValue.IsNull() || !request.PlanValue.IsUnknown() {
+		return
+	}
+
+	var name, inStateName, project, inStateProject types.String
+	request.Plan.GetAttribute(ctx, path.Root("name"), &name)
+	request.State.GetAttribute(ctx, path.Root("name"), &inStateName)
+	request.Plan.GetAttribute(ctx, path.Root("project"), &project)
+	request.State.GetAttribute(ctx, path.Root("project"), &inStateProject)
+
+	if name.Equal(inStateName) && project.Equal(inStateProject) {
+		response.PlanValue = request.StateValue
+	}
+}
+
+// retryInitialization plans the retry of an initialization that failed when the
+// repository was created, and otherwise keeps the state.
+type retryInitialization struct {
+}
+
+func (r retryInitialization) Description(ctx context.Context) string {
+	return "A failed initialization is retried on the next apply."
+}
+
+func (r retryInitialization) MarkdownDescription(ctx context.Context) string {
+	return "A failed initialization is retried on the next apply."
+}
+
+func (r retryInitialization) PlanModifyBool(ctx context.Context, request planmodifier.BoolRequest, response *planmodifier.BoolResponse) {
+	if request.StateValue.IsNull() || request.Plan.Raw.IsNull() {
+		return
+	}
+
+	response.PlanValue = types.BoolValue(true)
+}
+
+func replaceIfProjectMoveNotAllowed(ctx context.Context, request planmodifier.StringRequest, response *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+	var allowProjectMove types.Bool
+	response.Diagnostics.Append(request.Plan.GetAttribute(ctx, path.Root("allow_project_move"), &allowProjectMove)...)
+
+	response.RequiresReplace = !allowProjectMove.ValueBool()
 }
 
 func (receiver *RepositoryResource) Schema(ctx context.Context, request resource.SchemaRequest, response *resource.SchemaResponse) {
 	response.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"retain_on_delete": schema.BoolAttribute{
-				Optional: true,
-				Computed: true,
-				Default:  booldefault.StaticBool(true),
+				Optional:           true,
+				Computed:           true,
+				Default:            booldefault.StaticBool(true),
+				DeprecationMessage: "Use delete_mode instead",
 			},
 			"archive_on_delete": schema.BoolAttribute{
+				Optional:           true,
+				Computed:           true,
+				Default:            booldefault.StaticBool(true),
+				DeprecationMessage: "Use delete_mode instead",
+			},
+			"delete_mode": schema.StringAttribute{
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(deleteModes...),
+				},
+				Description: "What happens to the repository on destroy: retain, archive, rename or delete. " +
+					"Archive falls back to rename on servers older than Bitbucket 8.0. " +
+					"When not set, retain_on_delete and archive_on_delete decide",
+			},
+			"archived": schema.BoolAttribute{
 				Optional: true,
 				Computed: true,
-				Default:  booldefault.StaticBool(true),
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+				Description: "Whether the repository is archived and read-only. Requires Bitbucket 8.0 or later",
 			},
 			"id": schema.StringAttribute{
 				Computed: true,
@@ -114,11 +187,38 @@ func (receiver *RepositoryResource) Schema(ctx context.Context, request resource
 					&createSlug{},
 				},
 			},
-			"name": schema.StringAttribute{
-				Required: true,
+			"http_clone_url": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					&useStateUnlessMoved{},
+				},
+			},
+			"ssh_clone_url": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					&useStateUnlessMoved{},
+				},
+			},
+			"browse_url": schema.StringAttribute{
+				Computed: true,
 				PlanModifiers: []planmodifier.String{
-					util.ReplaceIfStringDiff(),
+					&useStateUnlessMoved{},
 				},
+			},
+			"scm_id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"state": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required: true,
 				Validators: []validator.String{
 					stringvalidator.RegexMatches(
 						regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9\-\s_.]*$`),
@@ -131,23 +231,144 @@ func (receiver *RepositoryResource) Schema(ctx context.Context, request resource
 				Optional:    true,
 				Description: "Repository description",
 			},
+			"forkable": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+				Description: "Whether the repository can be forked",
+			},
+			"public": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+				Description: "Whether the repository can be read anonymously",
+			},
 			"project": schema.StringAttribute{
 				Required: true,
 				PlanModifiers: []planmodifier.String{
-					util.ReplaceIfStringDiff(),
+					stringplanmodifier.RequiresReplaceIf(
+						replaceIfProjectMoveNotAllowed,
+						"If allow_project_move is not set, changing the project destroys and recreates the repository.",
+						"If `allow_project_move` is not set, changing the project destroys and recreates the repository.",
+					),
+				},
+			},
+			"allow_project_move": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Move the repository to the new project in place when project changes, instead of recreating it",
+			},
+			"default_branch": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
 				},
+				Description: "Default branch, which is also the branch that readme, path or template is committed to. The default branch of the server is used when not set",
 			},
 			"readme": schema.StringAttribute{
 				Optional: true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
 				},
+				Description: "Content of the README.md that initializes the repository. Only one of readme, path, template and import_from can be set",
 			},
 			"path": schema.StringAttribute{
 				Optional: true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
 				},
+				Description: "Local directory whose files initialize the repository. Only one of readme, path, template and import_from can be set",
+			},
+			"path_digest": schema.StringAttribute{
+				Computed:    true,
+				Description: "Combined hash of the files under path that initialize the repository",
+			},
+			"exclude": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Patterns in .gitignore syntax of files under path that are not committed, in addition to .git and the .gitignore files of path",
+			},
+			"initial_branches": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Additional branches created from the seed commit of readme, path or template",
+			},
+			"initial_tags": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Tags created on the seed commit of readme, path or template",
+			},
+			"seed_variables": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "When set, readme and the files under path ending in .tmpl are rendered as Go templates, and " +
+					"committed without the .tmpl suffix, with these variables next to ProjectKey, Slug, Name, CloneURL, " +
+					"SshCloneURL, BrowseURL and DefaultBranch",
+			},
+			"template": schema.SingleNestedAttribute{
+				Optional: true,
+				Attributes: map[string]schema.Attribute{
+					"project": schema.StringAttribute{
+						Required: true,
+					},
+					"repo": schema.StringAttribute{
+						Required: true,
+					},
+					"ref": schema.StringAttribute{
+						Optional:    true,
+						Description: "Branch or fully qualified ref to copy, the default branch of the template when not set",
+					},
+					"squash": schema.BoolAttribute{
+						Optional:    true,
+						Description: "Push the template tree as a single Initial Commit instead of its history",
+					},
+				},
+				Description: "Repository whose content initializes the new repository",
+			},
+			"import_from": schema.SingleNestedAttribute{
+				Optional: true,
+				Attributes: map[string]schema.Attribute{
+					"url": schema.StringAttribute{
+						Required: true,
+					},
+					"username": schema.StringAttribute{
+						Optional: true,
+					},
+					"password_wo": schema.StringAttribute{
+						Optional:  true,
+						Sensitive: true,
+						WriteOnly: true,
+					},
+				},
+				Description: "External git repository whose branches and tags are imported when the repository is created",
+			},
+			"imported_refs": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+				Description: "Branches and tags imported from import_from",
+			},
+			"initialized": schema.BoolAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.Bool{
+					retryInitialization{},
+				},
+				Description: "Whether readme, path or template was committed. A failed initialization is retried on the next apply",
+			},
+			"signing_key_fingerprint": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+				Description: "Fingerprint of the commit_signing key that signed the seed commit of readme, path or a squashed template",
 			},
 			"assignment_version": schema.StringAttribute{
 				Optional: true,
@@ -165,6 +386,117 @@ func (receiver *RepositoryResource) Configure(ctx context.Context, request resou
 	ConfigureResource(receiver, ctx, request, response)
 }
 
+// ValidateConfig rejects more than one source of the initial content, which would
+// otherwise be picked silently.
+func (receiver *RepositoryResource) ValidateConfig(ctx context.Context, request resource.ValidateConfigRequest, response *resource.ValidateConfigResponse) {
+	var (
+		readme, source       types.String
+		template, importFrom types.Object
+	)
+
+	if util.TestDiagnostics(&response.Diagnostics,
+		request.Config.GetAttribute(ctx, path.Root("readme"), &readme),
+		request.Config.GetAttribute(ctx, path.Root("path"), &source),
+		request.Config.GetAttribute(ctx, path.Root("template"), &template),
+		request.Config.GetAttribute(ctx, path.Root("import_from"), &importFrom)) {
+		return
+	}
+
+	configured := make([]string, 0)
+	if !readme.IsNull() {
+		configured = append(configured, "readme")
+	}
+	if !source.IsNull() {
+		configured = append(configured, "path")
+	}
+	if !template.IsNull() {
+		configured = append(configured, "template")
+	}
+	if !importFrom.IsNull() {
+		configured = append(configured, "import_from")
+	}
+
+	if len(configured) > 1 {
+		response.Diagnostics.AddAttributeError(path.Root(configured[1]), "Invalid Configuration",
+			fmt.Sprintf("Only one of readme, path, template and import_from can be set, got %s.", strings.Join(configured, " and ")),
+		)
+	}
+
+	if importFrom.IsNull() {
+		return
+	}
+
+	// the refs of an import come from the imported repository
+	for _, attribute := range []string{"initial_branches", "initial_tags"} {
+		var refs types.List
+		if util.TestDiagnostic(&response.Diagnostics, request.Config.GetAttribute(ctx, path.Root(attribute), &refs)) {
+			return
+		}
+
+		if !refs.IsNull() {
+			response.Diagnostics.AddAttributeError(path.Root(attribute), "Invalid Configuration",
+				fmt.Sprintf("'%s' can not be set together with 'import_from'.", attribute),
+			)
+		}
+	}
+}
+
+// ModifyPlan checks path before the repository is created, and records the digest
+// of the files it would be initialized with.
+func (receiver *RepositoryResource) ModifyPlan(ctx context.Context, request resource.ModifyPlanRequest, response *resource.ModifyPlanResponse) {
+	if request.Plan.Raw.IsNull() {
+		return
+	}
+
+	var (
+		source, inStateDigest types.String
+		initialized           types.Bool
+		exclude               types.List
+	)
+
+	if !request.State.Raw.IsNull() {
+		if util.TestDiagnostics(&response.Diagnostics,
+			request.State.GetAttribute(ctx, path.Root("initialized"), &initialized),
+			request.State.GetAttribute(ctx, path.Root("path_digest"), &inStateDigest)) {
+			return
+		}
+
+		// path is only seeded into a new repository, or when that failed
+		if initialized.IsNull() || initialized.ValueBool() {
+			response.Diagnostics.Append(response.Plan.SetAttribute(ctx, path.Root("path_digest"), inStateDigest)...)
+			return
+		}
+	}
+
+	if util.TestDiagnostics(&response.Diagnostics,
+		request.Plan.GetAttribute(ctx, path.Root("path"), &source),
+		request.Plan.GetAttribute(ctx, path.Root("exclude"), &exclude)) {
+		return
+	}
+
+	if source.IsNull() {
+		response.Diagnostics.Append(response.Plan.SetAttribute(ctx, path.Root("path_digest"), types.StringNull())...)
+		return
+	}
+
+	if source.IsUnknown() || exclude.IsUnknown() {
+		return
+	}
+
+	var patterns []string
+	if util.TestDiagnostic(&response.Diagnostics, exclude.ElementsAs(ctx, &patterns, false)) {
+		return
+	}
+
+	digest, err := seedPathDigest(source.ValueString(), patterns)
+	if err != nil {
+		response.Diagnostics.AddAttributeError(path.Root("path"), "Invalid path", err.Error())
+		return
+	}
+
+	response.Diagnostics.Append(response.Plan.SetAttribute(ctx, path.Root("path_digest"), types.StringValue(digest))...)
+}
+
 func (receiver *RepositoryResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
 	var (
 		plan  RepositoryModel
@@ -176,9 +508,11 @@ func (receiver *RepositoryResource) Create(ctx context.Context, request resource
 		return
 	}
 
-	repository, err := receiver.client.RepositoryService().Create(plan.Project.ValueString(), bitbucket.CreateRepo{
+	repository, err := receiver.extension.CreateRepository(plan.Project.ValueString(), RepositorySettings{
 		Name:        plan.Name.ValueString(),
 		Description: plan.Description.ValueString(),
+		Forkable:    boolPointer(plan.Forkable),
+		Public:      boolPointer(plan.Public),
 	})
 	if util.TestError(&response.Diagnostics, err, errorFailedToCreateRepository) {
 		return
@@ -186,6 +520,16 @@ func (receiver *RepositoryResource) Create(ctx context.Context, request resource
 
 	plan.Slug = types.StringValue(repository.Slug)
 
+	// the repository is archived only after it has been initialized
+	archived := plan.Archived.ValueBool()
+	plan.Archived = types.BoolValue(false)
+
+	defaultBranch := ""
+	if !plan.DefaultBranch.IsUnknown() {
+		defaultBranch = plan.DefaultBranch.ValueString()
+	}
+	plan.DefaultBranch = types.StringNull()
+
 	if util.TestDiagnostics(
 		&response.Diagnostics,
 		response.State.SetAttribute(ctx, path.Root("id"), types.StringValue(strconv.Itoa(repository.ID))),
@@ -195,127 +539,93 @@ func (receiver *RepositoryResource) Create(ctx context.Context, request resource
 		return
 	}
 
-	computation, diags := CreateRepositoryAssignments(ctx, receiver, plan)
-	if util.TestDiagnostic(&response.Diagnostics, diags) {
-		return
-	}
-
-	repositoryModel := NewRepositoryModel(repository, plan, computation)
-
-	diags = response.State.Set(ctx, repositoryModel)
-	if util.TestDiagnostic(&response.Diagnostics, diags) {
-		return
-	}
-	if !plan.Readme.IsNull() {
-		_, err = receiver.client.RepositoryService().Initialize(
-			plan.Project.ValueString(),
-			plan.Name.ValueString(),
-			plan.Readme.ValueString(),
-		)
-		if util.TestError(&response.Diagnostics, err, errorFailedToInitializeRepository) {
+	plan.ImportedRefs = types.ListNull(types.StringType)
+	if plan.ImportFrom != nil {
+		var password types.String
+		diags = request.Config.GetAttribute(ctx, path.Root("import_from").AtName("password_wo"), &password)
+		if util.TestDiagnostic(&response.Diagnostics, diags) {
 			return
 		}
-	} else if !plan.Path.IsNull() {
-		// plan.Project need top be lower case
 
-		repo, err := git.Init(memory.NewStorage(), memfs.New())
-		if util.TestError(&response.Diagnostics, err, errorFailedToInitializeRepository) {
+		// the repository is imported before it is shared with anyone
+		refs, sourceDefaultBranch, err := receiver.importRepository(ctx, repository, *plan.ImportFrom, password.ValueString())
+		if util.TestError(&response.Diagnostics, err, errorFailedToImportRepository) {
 			return
 		}
 
-		worktree, err := repo.Worktree()
+		plan.ImportedRefs, diags = types.ListValueFrom(ctx, types.StringType, refs)
+		if util.TestDiagnostic(&response.Diagnostics, diags) {
+			return
+		}
 
-		// copy filesystem to worktree recursively
-		err = filepath.Walk(plan.Path.ValueString(), func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
+		if defaultBranch == "" {
+			defaultBranch = sourceDefaultBranch
+		}
+	}
 
-			if info.IsDir() {
-				return nil // skip directories
-			}
+	// a template is only signed when squashed, its own commits are pushed as they are
+	plan.SigningKeyFingerprint = types.StringNull()
+	if plan.ImportFrom == nil && (!plan.Readme.IsNull() || !plan.Path.IsNull() || (plan.Template != nil && plan.Template.Squash.ValueBool())) {
+		plan.SigningKeyFingerprint = signingKeyFingerprint(receiver.config)
+	}
 
-			srcFile, err := os.Open(path)
-			if err != nil {
-				return err
-			}
-			defer srcFile.Close()
+	computation, diags := CreateRepositoryAssignments(ctx, receiver, plan)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
 
-			relativePath, err := filepath.Rel(plan.Path.ValueString(), path)
-			if err != nil {
-				return err
-			}
+	plan.Initialized = types.BoolValue(false)
+	plan.PathDigest = plan.resolvePathDigest()
+	repositoryModel := NewRepositoryModel(repository, plan, computation)
 
-			destPath := filepath.Join(worktree.Filesystem.Root(), relativePath)
-			destFile, err := worktree.Filesystem.Create(destPath)
-			if err != nil {
-				return err
-			}
+	diags = response.State.Set(ctx, repositoryModel)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
 
-			_, err = io.Copy(destFile, srcFile)
-			if err != nil {
-				return err
-			}
+	// a failed initialization keeps the repository, and is retried by the next apply
+	initialized := true
+	err = receiver.initializeRepository(ctx, repository, plan, defaultBranch)
+	if err != nil {
+		initialized = false
+		response.Diagnostics.AddWarning(errorFailedToInitializeRepository,
+			fmt.Sprintf("%s\n\nThe repository was created empty, initialization is retried on the next apply.", err.Error()),
+		)
+	}
 
-			return destFile.Close()
-		})
+	diags = response.State.SetAttribute(ctx, path.Root("initialized"), types.BoolValue(initialized))
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
 
-		if util.TestError(&response.Diagnostics, err, errorFailedToInitializeRepository) {
+	if defaultBranch != "" {
+		err = receiver.extension.SetDefaultBranch(plan.Project.ValueString(), repository.Slug, defaultBranch)
+		if util.TestError(&response.Diagnostics, err, errorFailedToSetDefaultBranch) {
 			return
 		}
+	}
 
-		_, err = worktree.Add(".")
-		if util.TestError(&response.Diagnostics, err, errorFailedToInitializeRepository) {
-			return
-		}
+	serverDefaultBranch, err := receiver.extension.GetDefaultBranch(plan.Project.ValueString(), repository.Slug)
+	if util.TestError(&response.Diagnostics, err, errorFailedToReadDefaultBranch) {
+		return
+	}
 
-		_, err = worktree.Commit("Initial Commit", &git.CommitOptions{
-			Author: &object.Signature{
-				Name:  receiver.config.Author.Name.ValueString(),
-				Email: receiver.config.Author.Email.ValueString(),
-				When:  time.Now(),
-			},
-		})
-		if util.TestError(&response.Diagnostics, err, errorFailedToInitializeRepository) {
-			return
-		}
+	diags = response.State.SetAttribute(ctx, path.Root("default_branch"), util.NullString(serverDefaultBranch))
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
 
-		_, err = repo.CreateRemote(&config.RemoteConfig{
-			Name: "origin",
-			URLs: []string{
-				fmt.Sprintf("%s/scm/%s/%s.git",
-					receiver.config.Bitbucket.EndPoint.ValueString(),
-					strings.ToLower(plan.Project.ValueString()),
-					repository.Slug),
-			}, // replace with your remote repo URL
-		})
-		if util.TestError(&response.Diagnostics, err, errorFailedToInitializeRepository) {
+	if archived {
+		err = receiver.setArchived(plan.Project.ValueString(), repository.Slug, true)
+		if util.TestError(&response.Diagnostics, err, errorFailedToArchiveRepository) {
 			return
 		}
 
-		var auth transport.AuthMethod
-		if receiver.config.Bitbucket.Token.IsNull() {
-			auth = &http.BasicAuth{
-				Username: receiver.config.Bitbucket.Username.ValueString(),
-				Password: receiver.config.Bitbucket.Password.ValueString(),
-			}
-		} else {
-			auth = &http.BasicAuth{
-				Username: receiver.config.Bitbucket.Username.ValueString(),
-				Password: receiver.config.Bitbucket.Token.ValueString(),
-			}
-		}
-		err = repo.Push(&git.PushOptions{
-			Auth: auth,
-			//RefSpecs: []config.RefSpec{
-			//	"refs/heads/master:refs/heads/master",
-			//},
-		})
-		if util.TestError(&response.Diagnostics, err, errorFailedToInitializeRepository) {
+		diags = response.State.SetAttribute(ctx, path.Root("archived"), types.BoolValue(true))
+		if util.TestDiagnostic(&response.Diagnostics, diags) {
 			return
 		}
 	}
-
 }
 
 func (receiver *RepositoryResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
@@ -331,8 +641,8 @@ func (receiver *RepositoryResource) Read(ctx context.Context, request resource.R
 		return
 	}
 
-	repository, err := receiver.client.RepositoryService().Read(state.Project.ValueString(), state.Slug.ValueString())
-	if util.TestError(&response.Diagnostics, err, errorFailedToCreateRepository) {
+	repository, err := receiver.extension.ReadRepository(state.Project.ValueString(), state.Slug.ValueString())
+	if util.TestError(&response.Diagnostics, err, errorFailedToReadRepository) {
 		return
 	}
 
@@ -341,7 +651,20 @@ func (receiver *RepositoryResource) Read(ctx context.Context, request resource.R
 		return
 	}
 
+	defaultBranch, err := receiver.extension.GetDefaultBranch(state.Project.ValueString(), state.Slug.ValueString())
+	if util.TestError(&response.Diagnostics, err, errorFailedToReadDefaultBranch) {
+		return
+	}
+
 	repositoryModel := NewRepositoryModel(repository, state, computation)
+	repositoryModel.Archived = types.BoolValue(repository.Archived)
+	if repositoryModel.Initialized.IsNull() {
+		// created before initialization was tracked
+		repositoryModel.Initialized = types.BoolValue(true)
+	}
+	if defaultBranch != "" {
+		repositoryModel.DefaultBranch = types.StringValue(defaultBranch)
+	}
 
 	diags = response.State.Set(ctx, repositoryModel)
 	if util.TestDiagnostic(&response.Diagnostics, diags) {
@@ -363,21 +686,106 @@ func (receiver *RepositoryResource) Update(ctx context.Context, request resource
 		return
 	}
 
-	repository, err := receiver.client.RepositoryService().Update(
+	if plan.Archived.IsUnknown() {
+		plan.Archived = types.BoolValue(state.Archived.ValueBool())
+	}
+
+	// initialization failed when the repository was created
+	retry := !state.Initialized.IsNull() && !state.Initialized.ValueBool()
+
+	// an archived repository is read-only, so unarchive it before anything else
+	if state.Archived.ValueBool() && (!plan.Archived.ValueBool() || retry) {
+		err = receiver.setArchived(state.Project.ValueString(), state.Slug.ValueString(), false)
+		if util.TestError(&response.Diagnostics, err, errorFailedToUnarchiveRepository) {
+			return
+		}
+	}
+
+	if !plan.Name.Equal(state.Name) {
+		renamed, err := receiver.extension.UpdateRepository(
+			state.Project.ValueString(),
+			state.Slug.ValueString(),
+			RepositorySettings{
+				Name:        plan.Name.ValueString(),
+				Description: plan.Description.ValueString(),
+				Forkable:    boolPointer(plan.Forkable),
+				Public:      boolPointer(plan.Public),
+			},
+		)
+		if util.TestError(&response.Diagnostics, err, errorFailedToRenameRepository) {
+			return
+		}
+
+		// the repository is now only reachable through the slug the server derived
+		// from the new name
+		state.Slug = types.StringValue(renamed.Slug)
+		plan.Slug = state.Slug
+	}
+
+	forceUpdate := !plan.AssignmentVersion.Equal(state.AssignmentVersion)
+	if !plan.Project.Equal(state.Project) {
+		_, err = receiver.extension.MoveRepository(
+			state.Project.ValueString(),
+			state.Slug.ValueString(),
+			plan.Project.ValueString(),
+		)
+		if util.TestError(&response.Diagnostics, err, errorFailedToMoveRepository) {
+			return
+		}
+
+		// assignments are applied again under the new project key
+		state.Project = plan.Project
+		forceUpdate = true
+	}
+
+	repository, err := receiver.extension.UpdateRepository(
 		state.Project.ValueString(),
 		state.Slug.ValueString(),
-		plan.Description.ValueString(),
+		RepositorySettings{
+			Description: plan.Description.ValueString(),
+			Forkable:    boolPointer(plan.Forkable),
+			Public:      boolPointer(plan.Public),
+		},
 	)
 	if util.TestError(&response.Diagnostics, err, errorFailedToUpdateRepository) {
 		return
 	}
 
-	forceUpdate := !plan.AssignmentVersion.Equal(state.AssignmentVersion)
 	computation, diags := UpdateRepositoryAssignments(ctx, receiver, plan, state, forceUpdate)
 	if util.TestDiagnostic(&response.Diagnostics, diags) {
 		return
 	}
 
+	plan.Initialized = types.BoolValue(true)
+	plan.PathDigest = plan.resolvePathDigest()
+	if retry {
+		defaultBranch := ""
+		if !plan.DefaultBranch.IsUnknown() {
+			defaultBranch = plan.DefaultBranch.ValueString()
+		}
+
+		err = receiver.initializeRepository(ctx, repository, plan, defaultBranch)
+		if util.TestError(&response.Diagnostics, err, errorFailedToInitializeRepository) {
+			return
+		}
+	}
+
+	if plan.DefaultBranch.IsUnknown() {
+		plan.DefaultBranch = state.DefaultBranch
+	} else if !plan.DefaultBranch.IsNull() && !plan.DefaultBranch.Equal(state.DefaultBranch) {
+		err = receiver.extension.SetDefaultBranch(plan.Project.ValueString(), repository.Slug, plan.DefaultBranch.ValueString())
+		if util.TestError(&response.Diagnostics, err, errorFailedToSetDefaultBranch) {
+			return
+		}
+	}
+
+	if plan.Archived.ValueBool() && (!state.Archived.ValueBool() || retry) {
+		err = receiver.setArchived(plan.Project.ValueString(), repository.Slug, true)
+		if util.TestError(&response.Diagnostics, err, errorFailedToArchiveRepository) {
+			return
+		}
+	}
+
 	repositoryModel := NewRepositoryModel(repository, plan, computation)
 
 	diags = response.State.Set(ctx, repositoryModel)
@@ -399,43 +807,105 @@ func (receiver *RepositoryResource) Delete(ctx context.Context, request resource
 		return
 	}
 
-	if !state.RetainOnDelete.ValueBool() {
-		diags = DeleteRepositoryAssignments(ctx, receiver, state)
-		if util.TestDiagnostic(&response.Diagnostics, diags) {
+	deleteMode := state.getDeleteMode()
+	if deleteMode == deleteModeRetain {
+		response.State.RemoveResource(ctx)
+		return
+	}
+
+	diags = DeleteRepositoryAssignments(ctx, receiver, state)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	if deleteMode == deleteModeArchive {
+		supported, err := receiver.extension.SupportsArchive()
+		if util.TestError(&response.Diagnostics, err, errorFailedToDeleteRepository) {
 			return
 		}
 
-		if state.ArchiveOnDelete.ValueBool() {
-
-			currentTime := time.Now().Format("2006-01-02-15-04-05")
-			err = receiver.client.RepositoryService().Rename(
-				state.Project.ValueString(),
-				state.Slug.ValueString(),
-				fmt.Sprintf("%s-archived-at-%s", state.Slug.ValueString(), currentTime),
-			)
-		} else {
-			err = receiver.client.RepositoryService().Delete(
-				state.Project.ValueString(),
-				state.Slug.ValueString(),
+		if !supported {
+			response.Diagnostics.AddWarning("Repository renamed instead of archived",
+				"The server does not support archiving repositories, which requires Bitbucket 8.0 or later.",
 			)
+			deleteMode = deleteModeRename
 		}
+	}
 
-		if util.TestError(&response.Diagnostics, err, errorFailedToDeleteRepository) {
-			return
+	switch deleteMode {
+	case deleteModeArchive:
+		if !state.Archived.ValueBool() {
+			err = receiver.extension.ArchiveRepository(state.Project.ValueString(), state.Slug.ValueString(), true)
 		}
+	case deleteModeRename:
+		currentTime := time.Now().Format("2006-01-02-15-04-05")
+		err = receiver.client.RepositoryService().Rename(
+			state.Project.ValueString(),
+			state.Slug.ValueString(),
+			fmt.Sprintf("%s-archived-at-%s", state.Slug.ValueString(), currentTime),
+		)
+	default:
+		err = receiver.client.RepositoryService().Delete(
+			state.Project.ValueString(),
+			state.Slug.ValueString(),
+		)
+	}
+
+	if util.TestError(&response.Diagnostics, err, errorFailedToDeleteRepository) {
+		return
 	}
 
 	response.State.RemoveResource(ctx)
 }
 
+// createInitialRefs creates initial_branches and initial_tags through the REST API,
+// for seeds that are not pushed with git and for retried initializations. Refs
+// that already exist are left as they are.
+func (receiver *RepositoryResource) createInitialRefs(plan RepositoryModel, slug string, commitId string) error {
+	for _, branch := range plan.InitialBranches {
+		err := receiver.extension.CreateBranch(plan.Project.ValueString(), slug, branch, commitId)
+		if err != nil && !errors.Is(err, errRefExists) {
+			return fmt.Errorf("failed to create branch %s: %w", branch, err)
+		}
+	}
+
+	for _, tag := range plan.InitialTags {
+		err := receiver.extension.CreateTag(plan.Project.ValueString(), slug, tag, commitId)
+		if err != nil && !errors.Is(err, errRefExists) {
+			return fmt.Errorf("failed to create tag %s: %w", tag, err)
+		}
+	}
+
+	return nil
+}
+
+// setArchived archives or unarchives the repository, failing early with a clear
+// message on servers that do not support it.
+func (receiver *RepositoryResource) setArchived(project, slug string, archived bool) error {
+	supported, err := receiver.extension.SupportsArchive()
+	if err != nil {
+		return err
+	}
+
+	if !supported {
+		return fmt.Errorf("archiving repositories requires Bitbucket 8.0 or later")
+	}
+
+	return receiver.extension.ArchiveRepository(project, slug, archived)
+}
+
 func (receiver *RepositoryResource) ImportState(ctx context.Context, request resource.ImportStateRequest, response *resource.ImportStateResponse) {
 	slug := strings.Split(request.ID, "/")
 	diags := response.State.Set(ctx, &RepositoryModel{
-		Project:        types.StringValue(slug[0]),
-		Slug:           types.StringValue(slug[1]),
-		Assignments:    types.ListNull(assignmentType),
-		ComputedUsers:  types.ListNull(computedAssignmentType),
-		ComputedGroups: types.ListNull(computedAssignmentType),
+		Project:               types.StringValue(slug[0]),
+		Slug:                  types.StringValue(slug[1]),
+		ImportedRefs:          types.ListNull(types.StringType),
+		SigningKeyFingerprint: types.StringNull(),
+		PathDigest:            types.StringNull(),
+		Initialized:           types.BoolValue(true),
+		Assignments:           types.ListNull(assignmentType),
+		ComputedUsers:         types.ListNull(computedAssignmentType),
+		ComputedGroups:        types.ListNull(computedAssignmentType),
 	})
 	if util.TestDiagnostic(&response.Diagnostics, diags) {
 		return
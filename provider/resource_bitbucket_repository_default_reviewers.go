@@ -7,18 +7,20 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/yunarta/terraform-atlassian-api-client/bitbucket"
 	"github.com/yunarta/terraform-provider-commons/util"
-	"sort"
+	"strings"
 )
 
 var (
 	_ resource.Resource              = &RepositoryDefaultReviewersResource{}
 	_ resource.ResourceWithConfigure = &RepositoryDefaultReviewersResource{}
 	_ ConfigurableReceiver           = &RepositoryDefaultReviewersResource{}
+	_ ExtendedReceiver               = &RepositoryDefaultReviewersResource{}
 )
 
 func NewRepositoryDefaultReviewersResource() resource.Resource {
@@ -26,8 +28,9 @@ func NewRepositoryDefaultReviewersResource() resource.Resource {
 }
 
 type RepositoryDefaultReviewersResource struct {
-	config BitbucketProviderConfig
-	client *bitbucket.Client
+	config    BitbucketProviderConfig
+	client    *bitbucket.Client
+	extension *ClientExtension
 }
 
 func (receiver *RepositoryDefaultReviewersResource) getClient() *bitbucket.Client {
@@ -39,6 +42,10 @@ func (receiver *RepositoryDefaultReviewersResource) setConfig(config BitbucketPr
 	receiver.client = client
 }
 
+func (receiver *RepositoryDefaultReviewersResource) setExtension(extension *ClientExtension) {
+	receiver.extension = extension
+}
+
 func (receiver *RepositoryDefaultReviewersResource) Metadata(ctx context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
 	response.TypeName = request.ProviderTypeName + "_repository_default_reviewers"
 }
@@ -86,6 +93,19 @@ func (receiver *RepositoryDefaultReviewersResource) Schema(ctx context.Context,
 			"requires": schema.Int64Attribute{
 				Optional: true,
 			},
+			"reviewer_groups": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Reviewer groups or user groups whose members are added as reviewers",
+			},
+			"unresolved_reviewers": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+				Description: "Configured reviewers and groups that could not be found",
+			},
 		},
 	}
 }
@@ -107,14 +127,27 @@ func (receiver *RepositoryDefaultReviewersResource) Create(ctx context.Context,
 		return
 	}
 
-	reviewers := make([]bitbucket.User, 0)
-	for _, user := range plan.Reviewers {
-		findUser, _ := receiver.client.UserService().FindUser(user)
-		if findUser != nil {
-			reviewers = append(reviewers, bitbucket.User{
-				Id: findUser.Id,
-			})
-		}
+	resolved, err := ResolveReviewers(receiver.client, receiver.extension, plan.Project.ValueString(), plan.Repository.ValueString(), plan.Reviewers, plan.ReviewerGroups)
+	if util.TestError(&response.Diagnostics, err, "Failed to resolve reviewers") {
+		return
+	}
+
+	if len(resolved.Unexpanded) > 0 {
+		response.Diagnostics.AddError("Failed to resolve reviewers",
+			fmt.Sprintf("The members of the user groups %s can only be listed with admin access.", strings.Join(resolved.Unexpanded, ", ")),
+		)
+		return
+	}
+
+	if len(resolved.Unresolved) > 0 {
+		response.Diagnostics.AddWarning("Unresolved reviewers",
+			fmt.Sprintf("The following reviewers could not be found: %s", strings.Join(resolved.Unresolved, ", ")),
+		)
+	}
+
+	plan.UnresolvedReviewers, diags = types.ListValueFrom(ctx, types.StringType, resolved.Unresolved)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
 	}
 
 	defaultReviewers := bitbucket.DefaultReviewers{
@@ -130,7 +163,7 @@ func (receiver *RepositoryDefaultReviewersResource) Create(ctx context.Context,
 				Id: refTypesMap[plan.TargetType.ValueString()],
 			},
 		},
-		Reviewers:         reviewers,
+		Reviewers:         resolved.Reviewers(),
 		RequiredApprovals: plan.Requires.ValueInt64(),
 	}
 
@@ -171,18 +204,27 @@ func (receiver *RepositoryDefaultReviewersResource) Read(ctx context.Context, re
 		return
 	}
 
-	users := make([]string, 0)
-	for _, user := range reviewers.Reviewers {
-		users = append(users, user.Name)
+	resolved, err := ResolveReviewers(receiver.client, receiver.extension, state.Project.ValueString(), state.Repository.ValueString(), state.Reviewers, state.ReviewerGroups)
+	if util.TestError(&response.Diagnostics, err, "Failed to resolve reviewers") {
+		return
 	}
 
-	sort.Strings(users)
+	if len(resolved.Unexpanded) > 0 {
+		response.Diagnostics.AddWarning("Reviewer groups not expanded",
+			fmt.Sprintf("The members of the user groups %s can only be listed with admin access, so reviewers added outside of Terraform are not detected.", strings.Join(resolved.Unexpanded, ", ")),
+		)
+	}
+
+	state.UnresolvedReviewers, diags = types.ListValueFrom(ctx, types.StringType, resolved.Unresolved)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
 
 	state.Source = types.StringValue(reviewers.SourceMatcher.Id)
 	state.SourceType = types.StringValue(refTypesReverseMap[reviewers.SourceMatcher.Type.Id])
 	state.Target = types.StringValue(reviewers.TargetMatcher.Id)
 	state.TargetType = types.StringValue(refTypesReverseMap[reviewers.TargetMatcher.Type.Id])
-	state.Reviewers = users
+	state.Reviewers = ReadReviewers(reviewers.Reviewers, state.Reviewers, resolved)
 	state.Requires = types.Int64Value(reviewers.RequiredApprovals)
 
 	diags = response.State.Set(ctx, state)
@@ -206,14 +248,27 @@ func (receiver *RepositoryDefaultReviewersResource) Update(ctx context.Context,
 		return
 	}
 
-	reviewers := make([]bitbucket.User, 0)
-	for _, user := range plan.Reviewers {
-		findUser, _ := receiver.client.UserService().FindUser(user)
-		if findUser != nil {
-			reviewers = append(reviewers, bitbucket.User{
-				Id: findUser.Id,
-			})
-		}
+	resolved, err := ResolveReviewers(receiver.client, receiver.extension, plan.Project.ValueString(), plan.Repository.ValueString(), plan.Reviewers, plan.ReviewerGroups)
+	if util.TestError(&response.Diagnostics, err, "Failed to resolve reviewers") {
+		return
+	}
+
+	if len(resolved.Unexpanded) > 0 {
+		response.Diagnostics.AddError("Failed to resolve reviewers",
+			fmt.Sprintf("The members of the user groups %s can only be listed with admin access.", strings.Join(resolved.Unexpanded, ", ")),
+		)
+		return
+	}
+
+	if len(resolved.Unresolved) > 0 {
+		response.Diagnostics.AddWarning("Unresolved reviewers",
+			fmt.Sprintf("The following reviewers could not be found: %s", strings.Join(resolved.Unresolved, ", ")),
+		)
+	}
+
+	plan.UnresolvedReviewers, diags = types.ListValueFrom(ctx, types.StringType, resolved.Unresolved)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
 	}
 
 	defaultReviewers := bitbucket.DefaultReviewers{
@@ -230,7 +285,7 @@ func (receiver *RepositoryDefaultReviewersResource) Update(ctx context.Context,
 				Id: refTypesMap[plan.TargetType.ValueString()],
 			},
 		},
-		Reviewers:         reviewers,
+		Reviewers:         resolved.Reviewers(),
 		RequiredApprovals: plan.Requires.ValueInt64(),
 	}
 
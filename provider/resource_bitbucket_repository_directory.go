@@ -0,0 +1,441 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yunarta/terraform-atlassian-api-client/bitbucket"
+	"github.com/yunarta/terraform-provider-commons/util"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	_ resource.Resource               = &RepositoryDirectoryResource{}
+	_ resource.ResourceWithConfigure  = &RepositoryDirectoryResource{}
+	_ resource.ResourceWithModifyPlan = &RepositoryDirectoryResource{}
+	_ ConfigurableReceiver            = &RepositoryDirectoryResource{}
+	_ ExtendedReceiver                = &RepositoryDirectoryResource{}
+)
+
+func NewRepositoryDirectoryResource() resource.Resource {
+	return &RepositoryDirectoryResource{}
+}
+
+type RepositoryDirectoryResource struct {
+	config    BitbucketProviderConfig
+	client    *bitbucket.Client
+	extension *ClientExtension
+}
+
+func (receiver *RepositoryDirectoryResource) getClient() *bitbucket.Client {
+	return receiver.client
+}
+
+func (receiver *RepositoryDirectoryResource) setConfig(config BitbucketProviderConfig, client *bitbucket.Client) {
+	receiver.config = config
+	receiver.client = client
+}
+
+func (receiver *RepositoryDirectoryResource) setExtension(extension *ClientExtension) {
+	receiver.extension = extension
+}
+
+func (receiver *RepositoryDirectoryResource) Metadata(ctx context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_repository_directory"
+}
+
+func (receiver *RepositoryDirectoryResource) Schema(ctx context.Context, request resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"project": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					util.ReplaceIfStringDiff(),
+				},
+			},
+			"repo": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					util.ReplaceIfStringDiff(),
+				},
+			},
+			"branch": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+				Description: "Branch to commit to, the default branch when not set",
+			},
+			"source": schema.StringAttribute{
+				Required:    true,
+				Description: "Local directory whose files are kept in sync",
+			},
+			"target_prefix": schema.StringAttribute{
+				Optional:    true,
+				Description: "Directory in the repository that receives the files, the root when not set",
+			},
+			"commit_message": schema.StringAttribute{
+				Optional:    true,
+				Description: "Message of the commits that change the files, defaults to Update followed by target_prefix, or Update files without one",
+			},
+			"files": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "SHA-256 of every managed file, keyed by its path in the repository",
+			},
+			"content_hash": schema.StringAttribute{
+				Computed:    true,
+				Description: "Combined hash of all managed files",
+			},
+			"commit_id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Last commit that changed the files through this resource",
+			},
+			"signing_key_fingerprint": schema.StringAttribute{
+				Computed:    true,
+				Description: "Fingerprint of the commit_signing key that signed commit_id",
+			},
+		},
+	}
+}
+
+func (receiver *RepositoryDirectoryResource) Configure(ctx context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
+	ConfigureResource(receiver, ctx, request, response)
+}
+
+func targetPrefix(prefix types.String) string {
+	return strings.Trim(prefix.ValueString(), "/")
+}
+
+// ModifyPlan hashes the local directory, so that the plan lists the files that
+// are added, modified or removed.
+func (receiver *RepositoryDirectoryResource) ModifyPlan(ctx context.Context, request resource.ModifyPlanRequest, response *resource.ModifyPlanResponse) {
+	if request.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan, state RepositoryDirectoryModel
+	if util.TestDiagnostic(&response.Diagnostics, request.Plan.Get(ctx, &plan)) {
+		return
+	}
+
+	if plan.Source.IsUnknown() || plan.TargetPrefix.IsUnknown() {
+		return
+	}
+
+	filter, err := newLocalTreeFilter(plan.Source.ValueString(), nil)
+	if err != nil {
+		response.Diagnostics.AddAttributeError(path.Root("source"), "Failed to read source directory", err.Error())
+		return
+	}
+
+	files, err := hashLocalTree(plan.Source.ValueString(), filter, targetPrefix(plan.TargetPrefix))
+	if err != nil {
+		response.Diagnostics.AddAttributeError(path.Root("source"), "Failed to read source directory", err.Error())
+		return
+	}
+
+	if util.TestDiagnostic(&response.Diagnostics, plan.setFiles(ctx, files)) {
+		return
+	}
+
+	if !request.State.Raw.IsNull() {
+		if util.TestDiagnostic(&response.Diagnostics, request.State.Get(ctx, &state)) {
+			return
+		}
+
+		if plan.ContentHash.Equal(state.ContentHash) {
+			plan.CommitId = state.CommitId
+			plan.SigningKeyFingerprint = state.SigningKeyFingerprint
+		} else {
+			plan.CommitId = types.StringUnknown()
+			plan.SigningKeyFingerprint = signingKeyFingerprint(receiver.config)
+		}
+	}
+
+	response.Diagnostics.Append(response.Plan.Set(ctx, plan)...)
+}
+
+// syncTree writes the files of the source directory, removes previously managed
+// files that are gone, and pushes a commit when anything changed. An empty
+// source removes every previously managed file.
+func (receiver *RepositoryDirectoryResource) syncTree(ctx context.Context, model RepositoryDirectoryModel, source string, previous map[string]string) (map[string]string, string, error) {
+	repository, err := receiver.extension.ReadRepository(model.Project.ValueString(), model.Repo.ValueString())
+	if err != nil {
+		return nil, "", err
+	}
+
+	repo, err := cloneBranch(ctx, receiver.config, repository, model.Branch.ValueString())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to clone %s: %w", model.Branch.ValueString(), err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, "", err
+	}
+
+	files := make(map[string]string)
+	if source != "" {
+		filter, err := newLocalTreeFilter(source, nil)
+		if err != nil {
+			return nil, "", err
+		}
+
+		prefix := targetPrefix(model.TargetPrefix)
+		err = walkLocalTree(source, filter, func(relativePath string, file string, info os.FileInfo) error {
+			destPath := filepath.ToSlash(filepath.Join(prefix, relativePath))
+			hash, err := copyAndHashToWorktree(worktree.Filesystem, file, info, destPath)
+			if err != nil {
+				return err
+			}
+
+			files[destPath] = hash
+			return nil
+		})
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	for file := range previous {
+		if _, found := files[file]; found {
+			continue
+		}
+
+		err = worktree.Filesystem.Remove(file)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, "", err
+		}
+	}
+
+	err = worktree.AddWithOptions(&git.AddOptions{All: true})
+	if err != nil {
+		return nil, "", err
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, "", err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if status.IsClean() {
+		return files, head.Hash().String(), nil
+	}
+
+	message := model.CommitMessage.ValueString()
+	if model.CommitMessage.IsNull() {
+		message = "Update files"
+		if prefix := targetPrefix(model.TargetPrefix); prefix != "" {
+			message = fmt.Sprintf("Update %s", prefix)
+		}
+	}
+
+	options, err := commitOptions(receiver.config)
+	if err != nil {
+		return nil, "", err
+	}
+
+	commit, err := worktree.Commit(message, options)
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = pushToBitbucket(ctx, receiver.config, repo, repository, branchRefSpec(model.Branch.ValueString()))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return files, commit.String(), nil
+}
+
+func (receiver *RepositoryDirectoryResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	var (
+		diags diag.Diagnostics
+
+		plan RepositoryDirectoryModel
+	)
+
+	diags = request.Plan.Get(ctx, &plan)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	if plan.Branch.IsUnknown() || plan.Branch.IsNull() {
+		branch, err := receiver.extension.GetDefaultBranch(plan.Project.ValueString(), plan.Repo.ValueString())
+		if util.TestError(&response.Diagnostics, err, errorFailedToReadDefaultBranch) {
+			return
+		}
+
+		if branch == "" {
+			response.Diagnostics.AddAttributeError(path.Root("branch"), errorFailedToSyncDirectory,
+				"The repository has no default branch yet, set branch explicitly.",
+			)
+			return
+		}
+		plan.Branch = types.StringValue(branch)
+	}
+
+	files, commitId, err := receiver.syncTree(ctx, plan, plan.Source.ValueString(), nil)
+	if util.TestError(&response.Diagnostics, err, errorFailedToSyncDirectory) {
+		return
+	}
+
+	plan.ID = types.StringValue(fmt.Sprintf("%s/%s/%s", plan.Project.ValueString(), plan.Repo.ValueString(), targetPrefix(plan.TargetPrefix)))
+	plan.CommitId = types.StringValue(commitId)
+	plan.SigningKeyFingerprint = signingKeyFingerprint(receiver.config)
+	if util.TestDiagnostic(&response.Diagnostics, plan.setFiles(ctx, files)) {
+		return
+	}
+
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
+}
+
+func (receiver *RepositoryDirectoryResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	var (
+		diags diag.Diagnostics
+
+		state RepositoryDirectoryModel
+	)
+
+	diags = request.State.Get(ctx, &state)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	previous, diags := state.getFiles(ctx)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	files, err := receiver.readTree(ctx, state, previous)
+	if util.TestError(&response.Diagnostics, err, errorFailedToReadDirectory) {
+		return
+	}
+
+	if util.TestDiagnostic(&response.Diagnostics, state.setFiles(ctx, files)) {
+		return
+	}
+
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+// readTree hashes the managed files as they are on the branch. Files that were
+// removed outside of Terraform are left out.
+func (receiver *RepositoryDirectoryResource) readTree(ctx context.Context, model RepositoryDirectoryModel, managed map[string]string) (map[string]string, error) {
+	repository, err := receiver.extension.ReadRepository(model.Project.ValueString(), model.Repo.ValueString())
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := cloneBranch(ctx, receiver.config, repository, model.Branch.ValueString())
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone %s: %w", model.Branch.ValueString(), err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string)
+	for file := range managed {
+		hash, err := hashWorktreeFile(worktree.Filesystem, file)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		files[file] = hash
+	}
+
+	return files, nil
+}
+
+func (receiver *RepositoryDirectoryResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	var (
+		diags diag.Diagnostics
+
+		plan, state RepositoryDirectoryModel
+	)
+
+	if util.TestDiagnostics(&response.Diagnostics,
+		request.Plan.Get(ctx, &plan),
+		request.State.Get(ctx, &state)) {
+		return
+	}
+
+	previous, diags := state.getFiles(ctx)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	files, commitId, err := receiver.syncTree(ctx, plan, plan.Source.ValueString(), previous)
+	if util.TestError(&response.Diagnostics, err, errorFailedToSyncDirectory) {
+		return
+	}
+
+	plan.ID = types.StringValue(fmt.Sprintf("%s/%s/%s", plan.Project.ValueString(), plan.Repo.ValueString(), targetPrefix(plan.TargetPrefix)))
+	plan.CommitId = state.CommitId
+	plan.SigningKeyFingerprint = state.SigningKeyFingerprint
+	if commitId != "" && !plan.ContentHash.Equal(state.ContentHash) {
+		plan.CommitId = types.StringValue(commitId)
+		plan.SigningKeyFingerprint = signingKeyFingerprint(receiver.config)
+	}
+	if util.TestDiagnostic(&response.Diagnostics, plan.setFiles(ctx, files)) {
+		return
+	}
+
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
+}
+
+func (receiver *RepositoryDirectoryResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	var (
+		diags diag.Diagnostics
+
+		state RepositoryDirectoryModel
+	)
+
+	diags = request.State.Get(ctx, &state)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	previous, diags := state.getFiles(ctx)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	_, _, err := receiver.syncTree(ctx, state, "", previous)
+	if util.TestError(&response.Diagnostics, err, errorFailedToSyncDirectory) {
+		return
+	}
+
+	response.State.RemoveResource(ctx)
+}
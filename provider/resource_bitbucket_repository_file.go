@@ -0,0 +1,412 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yunarta/terraform-atlassian-api-client/bitbucket"
+	"github.com/yunarta/terraform-provider-commons/util"
+)
+
+var (
+	_ resource.Resource              = &RepositoryFileResource{}
+	_ resource.ResourceWithConfigure = &RepositoryFileResource{}
+	_ ConfigurableReceiver           = &RepositoryFileResource{}
+	_ ExtendedReceiver               = &RepositoryFileResource{}
+)
+
+func NewRepositoryFileResource() resource.Resource {
+	return &RepositoryFileResource{}
+}
+
+type RepositoryFileResource struct {
+	config    BitbucketProviderConfig
+	client    *bitbucket.Client
+	extension *ClientExtension
+}
+
+func (receiver *RepositoryFileResource) getClient() *bitbucket.Client {
+	return receiver.client
+}
+
+func (receiver *RepositoryFileResource) setConfig(config BitbucketProviderConfig, client *bitbucket.Client) {
+	receiver.config = config
+	receiver.client = client
+}
+
+func (receiver *RepositoryFileResource) setExtension(extension *ClientExtension) {
+	receiver.extension = extension
+}
+
+func (receiver *RepositoryFileResource) Metadata(ctx context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_repository_file"
+}
+
+// planContentSha256 plans the hash of the configured content, so that it is only
+// unknown when the content itself is.
+type planContentSha256 struct {
+}
+
+func (r planContentSha256) Description(ctx context.Context) string {
+	return "The SHA-256 of content."
+}
+
+func (r planContentSha256) MarkdownDescription(ctx context.Context) string {
+	return "The SHA-256 of `content`."
+}
+
+func (r planContentSha256) PlanModifyString(ctx context.Context, request planmodifier.StringRequest, response *planmodifier.StringResponse) {
+	var content types.String
+	request.Plan.GetAttribute(ctx, path.Root("content"), &content)
+
+	if !content.IsUnknown() && !content.IsNull() {
+		response.PlanValue = types.StringValue(contentSha256(content.ValueString()))
+	}
+}
+
+func (receiver *RepositoryFileResource) Schema(ctx context.Context, request resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"project": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					util.ReplaceIfStringDiff(),
+				},
+			},
+			"repo": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					util.ReplaceIfStringDiff(),
+				},
+			},
+			"branch": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+				Description: "Branch to commit to, the default branch when not set",
+			},
+			"file_path": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					util.ReplaceIfStringDiff(),
+				},
+			},
+			"content": schema.StringAttribute{
+				Required: true,
+			},
+			"commit_message": schema.StringAttribute{
+				Optional:    true,
+				Description: "Message of the commits that change the file, defaults to Create, Update or Delete followed by the file path",
+			},
+			"content_sha256": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					&planContentSha256{},
+				},
+			},
+			"commit_id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Last commit that changed the file, refreshed when the file is changed outside of Terraform",
+			},
+		},
+	}
+}
+
+func (receiver *RepositoryFileResource) Configure(ctx context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
+	ConfigureResource(receiver, ctx, request, response)
+}
+
+func (receiver *RepositoryFileResource) commitMessage(plan RepositoryFileModel, action string) string {
+	if !plan.CommitMessage.IsNull() {
+		return plan.CommitMessage.ValueString()
+	}
+
+	return fmt.Sprintf("%s %s", action, plan.FilePath.ValueString())
+}
+
+// commitFile commits the planned content unless the branch already has it, and
+// returns the commit that holds the content. The commit goes through git when
+// commit_signing is configured, as the REST API can not sign commits.
+func (receiver *RepositoryFileResource) commitFile(ctx context.Context, plan RepositoryFileModel) (*Commit, error) {
+	project, repo, branch := plan.Project.ValueString(), plan.Repo.ValueString(), plan.Branch.ValueString()
+
+	content, found, err := receiver.extension.GetRawFile(project, repo, branch, plan.FilePath.ValueString())
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := receiver.extension.GetBranchHead(project, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	if found && content == plan.Content.ValueString() {
+		return head, nil
+	}
+
+	signer, err := newCommitSigner(receiver.config)
+	if err != nil {
+		return nil, err
+	}
+
+	if signer != nil {
+		if head == nil {
+			return nil, fmt.Errorf("branch %s does not exist, signed commits can only be added to an existing branch", branch)
+		}
+
+		action := "Create"
+		if found {
+			action = "Update"
+		}
+
+		return receiver.commitSignedFile(ctx, plan, receiver.commitMessage(plan, action))
+	}
+
+	file := EditFile{
+		Branch:  branch,
+		Path:    plan.FilePath.ValueString(),
+		Content: plan.Content.ValueString(),
+		Message: receiver.commitMessage(plan, "Create"),
+	}
+	if found {
+		file.Message = receiver.commitMessage(plan, "Update")
+		if head != nil {
+			file.SourceCommitId = head.Id
+		}
+	}
+
+	return receiver.extension.EditFile(project, repo, file)
+}
+
+func (receiver *RepositoryFileResource) commitSignedFile(ctx context.Context, plan RepositoryFileModel, message string) (*Commit, error) {
+	repository, err := receiver.extension.ReadRepository(plan.Project.ValueString(), plan.Repo.ValueString())
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := cloneBranch(ctx, receiver.config, repository, plan.Branch.ValueString())
+	if err != nil {
+		return nil, err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeWorktreeFile(worktree, plan.FilePath.ValueString(), plan.Content.ValueString())
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := commitOptions(receiver.config)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := worktree.Commit(message, options)
+	if err != nil {
+		return nil, err
+	}
+
+	err = pushToBitbucket(ctx, receiver.config, repo, repository, branchRefSpec(plan.Branch.ValueString()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Commit{Id: hash.String(), DisplayId: hash.String()[:11]}, nil
+}
+
+func (receiver *RepositoryFileResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	var (
+		diags diag.Diagnostics
+
+		plan RepositoryFileModel
+	)
+
+	diags = request.Plan.Get(ctx, &plan)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	if plan.Branch.IsUnknown() || plan.Branch.IsNull() {
+		branch, err := receiver.extension.GetDefaultBranch(plan.Project.ValueString(), plan.Repo.ValueString())
+		if util.TestError(&response.Diagnostics, err, errorFailedToReadDefaultBranch) {
+			return
+		}
+
+		if branch == "" {
+			response.Diagnostics.AddAttributeError(path.Root("branch"), errorFailedToCommitFile,
+				"The repository has no default branch yet, set branch explicitly.",
+			)
+			return
+		}
+		plan.Branch = types.StringValue(branch)
+	}
+
+	commit, err := receiver.commitFile(ctx, plan)
+	if util.TestError(&response.Diagnostics, err, errorFailedToCommitFile) {
+		return
+	}
+
+	plan.ID = types.StringValue(fmt.Sprintf("%s/%s/%s", plan.Project.ValueString(), plan.Repo.ValueString(), plan.FilePath.ValueString()))
+	plan.ContentSha256 = types.StringValue(contentSha256(plan.Content.ValueString()))
+	plan.CommitId = types.StringNull()
+	if commit != nil {
+		plan.CommitId = types.StringValue(commit.Id)
+	}
+
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
+}
+
+func (receiver *RepositoryFileResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	var (
+		diags diag.Diagnostics
+
+		state RepositoryFileModel
+	)
+
+	diags = request.State.Get(ctx, &state)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	content, found, err := receiver.extension.GetRawFile(state.Project.ValueString(), state.Repo.ValueString(),
+		state.Branch.ValueString(), state.FilePath.ValueString())
+	if util.TestError(&response.Diagnostics, err, errorFailedToReadFile) {
+		return
+	}
+
+	if !found {
+		response.State.RemoveResource(ctx)
+		return
+	}
+
+	// someone changed the file outside of Terraform
+	if hash := contentSha256(content); hash != state.ContentSha256.ValueString() {
+		state.Content = types.StringValue(content)
+		state.ContentSha256 = types.StringValue(hash)
+
+		commit, err := receiver.extension.GetFileCommit(state.Project.ValueString(), state.Repo.ValueString(),
+			state.Branch.ValueString(), state.FilePath.ValueString())
+		if util.TestError(&response.Diagnostics, err, errorFailedToReadFile) {
+			return
+		}
+
+		if commit != nil {
+			state.CommitId = types.StringValue(commit.Id)
+		}
+	}
+
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (receiver *RepositoryFileResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	var (
+		diags diag.Diagnostics
+
+		plan, state RepositoryFileModel
+	)
+
+	if util.TestDiagnostics(&response.Diagnostics,
+		request.Plan.Get(ctx, &plan),
+		request.State.Get(ctx, &state)) {
+		return
+	}
+
+	plan.CommitId = state.CommitId
+	if !plan.Content.Equal(state.Content) {
+		commit, err := receiver.commitFile(ctx, plan)
+		if util.TestError(&response.Diagnostics, err, errorFailedToCommitFile) {
+			return
+		}
+
+		if commit != nil {
+			plan.CommitId = types.StringValue(commit.Id)
+		}
+	}
+
+	plan.ContentSha256 = types.StringValue(contentSha256(plan.Content.ValueString()))
+
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
+}
+
+func (receiver *RepositoryFileResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	var (
+		diags diag.Diagnostics
+
+		state RepositoryFileModel
+	)
+
+	diags = request.State.Get(ctx, &state)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	err := receiver.deleteFile(ctx, state)
+	if util.TestError(&response.Diagnostics, err, errorFailedToDeleteFile) {
+		return
+	}
+
+	response.State.RemoveResource(ctx)
+}
+
+// deleteFile removes the file with a commit pushed through git, as the file edit
+// API cannot delete files.
+func (receiver *RepositoryFileResource) deleteFile(ctx context.Context, state RepositoryFileModel) error {
+	repository, err := receiver.extension.ReadRepository(state.Project.ValueString(), state.Repo.ValueString())
+	if err != nil {
+		return err
+	}
+
+	repo, err := cloneBranch(ctx, receiver.config, repository, state.Branch.ValueString())
+	if err != nil {
+		return err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	_, err = worktree.Filesystem.Stat(state.FilePath.ValueString())
+	if err != nil {
+		// already removed outside of Terraform
+		return nil
+	}
+
+	_, err = worktree.Remove(state.FilePath.ValueString())
+	if err != nil {
+		return err
+	}
+
+	options, err := commitOptions(receiver.config)
+	if err != nil {
+		return err
+	}
+
+	_, err = worktree.Commit(receiver.commitMessage(state, "Delete"), options)
+	if err != nil {
+		return err
+	}
+
+	return pushToBitbucket(ctx, receiver.config, repo, repository, branchRefSpec(state.Branch.ValueString()))
+}
@@ -0,0 +1,345 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yunarta/terraform-atlassian-api-client/bitbucket"
+	"github.com/yunarta/terraform-provider-commons/util"
+	"strings"
+	"time"
+)
+
+var (
+	_ resource.Resource                = &RepositoryForkResource{}
+	_ resource.ResourceWithConfigure   = &RepositoryForkResource{}
+	_ resource.ResourceWithImportState = &RepositoryForkResource{}
+	_ RepositoryPermissionReceiver     = &RepositoryForkResource{}
+	_ ConfigurableReceiver             = &RepositoryForkResource{}
+	_ ExtendedReceiver                 = &RepositoryForkResource{}
+)
+
+const repositoryStateAvailable = "AVAILABLE"
+
+func NewRepositoryForkResource() resource.Resource {
+	return &RepositoryForkResource{}
+}
+
+type RepositoryForkResource struct {
+	config    BitbucketProviderConfig
+	client    *bitbucket.Client
+	extension *ClientExtension
+}
+
+func (receiver *RepositoryForkResource) getClient() *bitbucket.Client {
+	return receiver.client
+}
+
+func (receiver *RepositoryForkResource) setConfig(config BitbucketProviderConfig, client *bitbucket.Client) {
+	receiver.config = config
+	receiver.client = client
+}
+
+func (receiver *RepositoryForkResource) setExtension(extension *ClientExtension) {
+	receiver.extension = extension
+}
+
+func (receiver *RepositoryForkResource) Metadata(ctx context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_repository_fork"
+}
+
+func (receiver *RepositoryForkResource) Schema(ctx context.Context, request resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"origin_project": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					util.ReplaceIfStringDiff(),
+				},
+				Description: "Project of the repository to fork",
+			},
+			"origin_repo": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					util.ReplaceIfStringDiff(),
+				},
+				Description: "Slug of the repository to fork",
+			},
+			"project": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					util.ReplaceIfStringDiff(),
+				},
+				Description: "Project that receives the fork",
+			},
+			"name": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					util.ReplaceIfStringDiff(),
+				},
+				Description: "Name of the fork",
+			},
+			"slug": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"http_clone_url": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"ssh_clone_url": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"ref_sync": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+				Description: "Keep the branches and tags of the fork in sync with the origin",
+			},
+			"assignment_version": schema.StringAttribute{
+				Optional: true,
+			},
+			"computed_users":  ComputedAssignmentSchema,
+			"computed_groups": ComputedAssignmentSchema,
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Create: true,
+			}),
+		},
+		Blocks: map[string]schema.Block{
+			"assignments": AssignmentSchema("REPO_ADMIN", "REPO_READ", "REPO_WRITE"),
+		},
+	}
+}
+
+func (receiver *RepositoryForkResource) Configure(ctx context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
+	ConfigureResource(receiver, ctx, request, response)
+}
+
+// waitUntilAvailable polls the fork until Bitbucket finished copying the origin.
+func (receiver *RepositoryForkResource) waitUntilAvailable(ctx context.Context, project, slug string, limit time.Duration) (*RepositoryDetails, error) {
+	timeout := time.After(limit)
+	for {
+		repository, err := receiver.extension.ReadRepository(project, slug)
+		if err != nil {
+			return nil, err
+		}
+
+		if repository.State == repositoryStateAvailable {
+			return repository, nil
+		} else if repository.State != "INITIALISING" {
+			return nil, fmt.Errorf("fork %s/%s ended in state %s", project, slug, repository.State)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout:
+			return nil, fmt.Errorf("timed out waiting for fork %s/%s to become available", project, slug)
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+func (receiver *RepositoryForkResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	var (
+		plan  RepositoryForkModel
+		diags diag.Diagnostics
+	)
+
+	diags = request.Plan.Get(ctx, &plan)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	fork, err := receiver.extension.ForkRepository(
+		plan.OriginProject.ValueString(),
+		plan.OriginRepo.ValueString(),
+		plan.Project.ValueString(),
+		plan.Name.ValueString(),
+	)
+	if util.TestError(&response.Diagnostics, err, errorFailedToForkRepository) {
+		return
+	}
+
+	plan.Slug = types.StringValue(fork.Slug)
+
+	if util.TestDiagnostics(
+		&response.Diagnostics,
+		response.State.SetAttribute(ctx, path.Root("id"), types.StringValue(fmt.Sprintf("%v", fork.ID))),
+		response.State.SetAttribute(ctx, path.Root("origin_project"), plan.OriginProject),
+		response.State.SetAttribute(ctx, path.Root("origin_repo"), plan.OriginRepo),
+		response.State.SetAttribute(ctx, path.Root("project"), plan.Project),
+		response.State.SetAttribute(ctx, path.Root("slug"), plan.Slug),
+	) {
+		return
+	}
+
+	createTimeout, diags := plan.Timeouts.Create(ctx, 10*time.Minute)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	repository, err := receiver.waitUntilAvailable(ctx, plan.Project.ValueString(), fork.Slug, createTimeout)
+	if util.TestError(&response.Diagnostics, err, errorFailedToForkRepository) {
+		return
+	}
+
+	if !plan.RefSync.IsUnknown() {
+		err = receiver.extension.SetRefSync(plan.Project.ValueString(), fork.Slug, plan.RefSync.ValueBool())
+		if util.TestError(&response.Diagnostics, err, errorFailedToUpdateRefSync) {
+			return
+		}
+	}
+
+	refSync, err := receiver.extension.GetRefSync(plan.Project.ValueString(), fork.Slug)
+	if util.TestError(&response.Diagnostics, err, errorFailedToReadRefSync) {
+		return
+	}
+
+	computation, diags := CreateRepositoryAssignments(ctx, receiver, plan)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	diags = response.State.Set(ctx, NewRepositoryForkModel(repository, refSync.Enabled, plan, computation))
+	response.Diagnostics.Append(diags...)
+}
+
+func (receiver *RepositoryForkResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	var (
+		diags diag.Diagnostics
+
+		state RepositoryForkModel
+	)
+
+	diags = request.State.Get(ctx, &state)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	repository, err := receiver.extension.ReadRepository(state.Project.ValueString(), state.Slug.ValueString())
+	if util.TestError(&response.Diagnostics, err, errorFailedToReadRepository) {
+		return
+	}
+
+	refSync, err := receiver.extension.GetRefSync(state.Project.ValueString(), state.Slug.ValueString())
+	if util.TestError(&response.Diagnostics, err, errorFailedToReadRefSync) {
+		return
+	}
+
+	computation, diags := ComputeRepositoryAssignments(ctx, receiver, state)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	diags = response.State.Set(ctx, NewRepositoryForkModel(repository, refSync.Enabled, state, computation))
+	response.Diagnostics.Append(diags...)
+}
+
+func (receiver *RepositoryForkResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	var (
+		diags diag.Diagnostics
+
+		plan, state RepositoryForkModel
+	)
+
+	if util.TestDiagnostics(&response.Diagnostics,
+		request.Plan.Get(ctx, &plan),
+		request.State.Get(ctx, &state)) {
+		return
+	}
+
+	if !plan.RefSync.IsUnknown() && !plan.RefSync.Equal(state.RefSync) {
+		err := receiver.extension.SetRefSync(state.Project.ValueString(), state.Slug.ValueString(), plan.RefSync.ValueBool())
+		if util.TestError(&response.Diagnostics, err, errorFailedToUpdateRefSync) {
+			return
+		}
+	}
+
+	repository, err := receiver.extension.ReadRepository(state.Project.ValueString(), state.Slug.ValueString())
+	if util.TestError(&response.Diagnostics, err, errorFailedToReadRepository) {
+		return
+	}
+
+	refSync, err := receiver.extension.GetRefSync(state.Project.ValueString(), state.Slug.ValueString())
+	if util.TestError(&response.Diagnostics, err, errorFailedToReadRefSync) {
+		return
+	}
+
+	forceUpdate := !plan.AssignmentVersion.Equal(state.AssignmentVersion)
+	computation, diags := UpdateRepositoryAssignments(ctx, receiver, plan, state, forceUpdate)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	diags = response.State.Set(ctx, NewRepositoryForkModel(repository, refSync.Enabled, plan, computation))
+	response.Diagnostics.Append(diags...)
+}
+
+func (receiver *RepositoryForkResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	var (
+		diags diag.Diagnostics
+
+		state RepositoryForkModel
+	)
+
+	diags = request.State.Get(ctx, &state)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	err := receiver.client.RepositoryService().Delete(state.Project.ValueString(), state.Slug.ValueString())
+	if util.TestError(&response.Diagnostics, err, errorFailedToDeleteRepository) {
+		return
+	}
+
+	response.State.RemoveResource(ctx)
+}
+
+func (receiver *RepositoryForkResource) ImportState(ctx context.Context, request resource.ImportStateRequest, response *resource.ImportStateResponse) {
+	slug := strings.Split(request.ID, "/")
+	if len(slug) != 2 {
+		response.Diagnostics.AddError("Invalid import id", fmt.Sprintf("Expected project/slug, got %s", request.ID))
+		return
+	}
+
+	diags := response.State.Set(ctx, &RepositoryForkModel{
+		Project: types.StringValue(slug[0]),
+		Slug:    types.StringValue(slug[1]),
+		Timeouts: timeouts.Value{
+			Object: types.ObjectNull(map[string]attr.Type{
+				"create": types.StringType,
+			}),
+		},
+		Assignments:    types.ListNull(assignmentType),
+		ComputedUsers:  types.ListNull(computedAssignmentType),
+		ComputedGroups: types.ListNull(computedAssignmentType),
+	})
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+}
@@ -0,0 +1,198 @@
+package provider
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yunarta/terraform-atlassian-api-client/bitbucket"
+	"github.com/yunarta/terraform-provider-commons/util"
+)
+
+var (
+	_ resource.Resource              = &RepositoryMergeCheckResource{}
+	_ resource.ResourceWithConfigure = &RepositoryMergeCheckResource{}
+	_ ConfigurableReceiver           = &RepositoryMergeCheckResource{}
+	_ ExtendedReceiver               = &RepositoryMergeCheckResource{}
+)
+
+func NewRepositoryMergeCheckResource() resource.Resource {
+	return &RepositoryMergeCheckResource{}
+}
+
+type RepositoryMergeCheckResource struct {
+	config    BitbucketProviderConfig
+	client    *bitbucket.Client
+	extension *ClientExtension
+}
+
+func (receiver *RepositoryMergeCheckResource) getClient() *bitbucket.Client {
+	return receiver.client
+}
+
+func (receiver *RepositoryMergeCheckResource) setConfig(config BitbucketProviderConfig, client *bitbucket.Client) {
+	receiver.config = config
+	receiver.client = client
+}
+
+func (receiver *RepositoryMergeCheckResource) setExtension(extension *ClientExtension) {
+	receiver.extension = extension
+}
+
+func (receiver *RepositoryMergeCheckResource) Metadata(ctx context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_repository_merge_check"
+}
+
+func (receiver *RepositoryMergeCheckResource) Schema(ctx context.Context, request resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"project": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					util.ReplaceIfStringDiff(),
+				},
+			},
+			"repo": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					util.ReplaceIfStringDiff(),
+				},
+			},
+			"key": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					util.ReplaceIfStringDiff(),
+				},
+				Description: "Hook key, such as com.atlassian.bitbucket.server.bitbucket-bundled-hooks:incomplete-tasks-merge-check",
+			},
+			"enabled": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(true),
+			},
+			"settings": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					&jsonSemanticEquality{},
+				},
+				Validators: []validator.String{
+					&jsonStringValidator{},
+				},
+				Description: "Hook settings as a JSON object",
+			},
+		},
+	}
+}
+
+func (receiver *RepositoryMergeCheckResource) Configure(ctx context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
+	ConfigureResource(receiver, ctx, request, response)
+}
+
+func (receiver *RepositoryMergeCheckResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	var (
+		diags diag.Diagnostics
+		err   error
+
+		plan RepositoryMergeCheckModel
+	)
+
+	diags = request.Plan.Get(ctx, &plan)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	err = applyMergeCheck(receiver.extension, plan.Project.ValueString(), plan.Repo.ValueString(), plan.Key.ValueString(), plan.Enabled, plan.Settings)
+	if util.TestError(&response.Diagnostics, err, "Failed to update merge check") {
+		return
+	}
+
+	plan.Enabled, plan.Settings, err = readMergeCheck(receiver.extension, plan.Project.ValueString(), plan.Repo.ValueString(), plan.Key.ValueString(), plan.Settings)
+	if util.TestError(&response.Diagnostics, err, "Failed to read merge check") {
+		return
+	}
+
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
+}
+
+func (receiver *RepositoryMergeCheckResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	var (
+		diags diag.Diagnostics
+		err   error
+
+		state RepositoryMergeCheckModel
+	)
+
+	diags = request.State.Get(ctx, &state)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	state.Enabled, state.Settings, err = readMergeCheck(receiver.extension, state.Project.ValueString(), state.Repo.ValueString(), state.Key.ValueString(), state.Settings)
+	if util.TestError(&response.Diagnostics, err, "Failed to read merge check") {
+		return
+	}
+
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (receiver *RepositoryMergeCheckResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	var (
+		diags diag.Diagnostics
+		err   error
+
+		plan, state RepositoryMergeCheckModel
+	)
+
+	if util.TestDiagnostics(&response.Diagnostics,
+		request.Plan.Get(ctx, &plan),
+		request.State.Get(ctx, &state)) {
+		return
+	}
+
+	settings := plan.Settings
+	if settings.Equal(state.Settings) {
+		settings = types.StringNull()
+	}
+
+	err = applyMergeCheck(receiver.extension, plan.Project.ValueString(), plan.Repo.ValueString(), plan.Key.ValueString(), plan.Enabled, settings)
+	if util.TestError(&response.Diagnostics, err, "Failed to update merge check") {
+		return
+	}
+
+	plan.Enabled, plan.Settings, err = readMergeCheck(receiver.extension, plan.Project.ValueString(), plan.Repo.ValueString(), plan.Key.ValueString(), plan.Settings)
+	if util.TestError(&response.Diagnostics, err, "Failed to read merge check") {
+		return
+	}
+
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
+}
+
+func (receiver *RepositoryMergeCheckResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	var (
+		diags diag.Diagnostics
+		err   error
+
+		state RepositoryMergeCheckModel
+	)
+
+	diags = request.State.Get(ctx, &state)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	// removing the repository setting restores the project setting of the check
+	err = receiver.extension.InheritHook(state.Project.ValueString(), state.Repo.ValueString(), state.Key.ValueString())
+	if util.TestError(&response.Diagnostics, err, "Failed to remove merge check") {
+		return
+	}
+
+	response.State.RemoveResource(ctx)
+}
@@ -9,13 +9,15 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/yunarta/terraform-atlassian-api-client/bitbucket"
 	"github.com/yunarta/terraform-provider-commons/util"
-	"strconv"
 )
 
 var (
 	_ resource.Resource              = &RepositoryMergeChecksResource{}
 	_ resource.ResourceWithConfigure = &RepositoryMergeChecksResource{}
 	_ ConfigurableReceiver           = &RepositoryMergeChecksResource{}
+	_ ExtendedReceiver               = &RepositoryMergeChecksResource{}
+
+	_ resource.ResourceWithValidateConfig = &RepositoryMergeChecksResource{}
 )
 
 func NewRepositoryMergeChecksResource() resource.Resource {
@@ -23,8 +25,9 @@ func NewRepositoryMergeChecksResource() resource.Resource {
 }
 
 type RepositoryMergeChecksResource struct {
-	config BitbucketProviderConfig
-	client *bitbucket.Client
+	config    BitbucketProviderConfig
+	client    *bitbucket.Client
+	extension *ClientExtension
 }
 
 func (receiver *RepositoryMergeChecksResource) getClient() *bitbucket.Client {
@@ -36,6 +39,10 @@ func (receiver *RepositoryMergeChecksResource) setConfig(config BitbucketProvide
 	receiver.client = client
 }
 
+func (receiver *RepositoryMergeChecksResource) setExtension(extension *ClientExtension) {
+	receiver.extension = extension
+}
+
 func (receiver *RepositoryMergeChecksResource) Metadata(ctx context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
 	response.TypeName = request.ProviderTypeName + "_repository_merge_checks"
 }
@@ -64,6 +71,9 @@ func (receiver *RepositoryMergeChecksResource) Schema(ctx context.Context, reque
 			"minimum_successful_builds": schema.Int64Attribute{
 				Optional: true,
 			},
+			"all_reviewer_approval_mode":     mergeCheckModeSchema,
+			"minimum_approvals_mode":         mergeCheckModeSchema,
+			"minimum_successful_builds_mode": mergeCheckModeSchema,
 		},
 	}
 }
@@ -72,6 +82,22 @@ func (receiver *RepositoryMergeChecksResource) Configure(ctx context.Context, re
 	ConfigureResource(receiver, ctx, request, response)
 }
 
+func (receiver *RepositoryMergeChecksResource) ValidateConfig(ctx context.Context, request resource.ValidateConfigRequest, response *resource.ValidateConfigResponse) {
+	var config RepositoryMergeChecksModel
+
+	diags := request.Config.Get(ctx, &config)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	validateMergeCheckMode(&response.Diagnostics, "all_reviewer_approval",
+		config.AllReviewerApprovalMode, config.AllReviewerApproval.IsNull() || !config.AllReviewerApproval.ValueBool(), false)
+	validateMergeCheckMode(&response.Diagnostics, "minimum_approvals",
+		config.MinimumApprovalMode, config.MinimumApproval.IsNull(), true)
+	validateMergeCheckMode(&response.Diagnostics, "minimum_successful_builds",
+		config.MinimumSuccessfulBuildMode, config.MinimumSuccessfulBuild.IsNull(), true)
+}
+
 func (receiver *RepositoryMergeChecksResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
 	var (
 		diags diag.Diagnostics
@@ -85,25 +111,9 @@ func (receiver *RepositoryMergeChecksResource) Create(ctx context.Context, reque
 		return
 	}
 
-	if plan.AllReviewerApproval.ValueBool() {
-		err = receiver.client.RepositoryService().EnableMergeCheck(plan.Project, plan.Repo, "com.atlassian.bitbucket.server.bitbucket-bundled-hooks:all-approvers-merge-check")
-		if util.TestError(&response.Diagnostics, err, "Failed to update all approvers merge check") {
-			return
-		}
-	}
-
-	if !plan.MinimumApproval.IsNull() {
-		err = receiver.client.RepositoryService().ConfigureMergeCheck(plan.Project, plan.Repo, "com.atlassian.bitbucket.server.bitbucket-bundled-hooks:requiredApproversMergeHook", int(plan.MinimumApproval.ValueInt64()))
-		if util.TestError(&response.Diagnostics, err, "Failed to update minimum approvers merge check") {
-			return
-		}
-	}
-
-	if !plan.MinimumSuccessfulBuild.IsNull() {
-		err = receiver.client.RepositoryService().ConfigureMergeCheck(plan.Project, plan.Repo, "com.atlassian.bitbucket.server.bitbucket-build:requiredBuildsMergeCheck", int(plan.MinimumSuccessfulBuild.ValueInt64()))
-		if util.TestError(&response.Diagnostics, err, "Failed to update minimum build merge check") {
-			return
-		}
+	err = receiver.applyChecks(plan, false)
+	if util.TestError(&response.Diagnostics, err, "Failed to update merge checks") {
+		return
 	}
 
 	diags = response.State.Set(ctx, plan)
@@ -123,50 +133,146 @@ func (receiver *RepositoryMergeChecksResource) Read(ctx context.Context, request
 		return
 	}
 
-	checks, err := receiver.client.RepositoryService().GetMergeChecks(state.Project, state.Repo)
-	if util.TestError(&response.Diagnostics, err, "Failed get merge checks") {
+	err = receiver.readMode(state, mergeCheckAllApprovers, &state.AllReviewerApprovalMode)
+	if util.TestError(&response.Diagnostics, err, "Failed to read all approvers merge check") {
 		return
 	}
 
-	// convert checks into map using it detail key
-	checksMap := make(map[string]bitbucket.MergeCheck)
-	for _, check := range checks {
-		checksMap[check.Details.Key] = check
+	err = receiver.readMode(state, mergeCheckRequiredApproval, &state.MinimumApprovalMode)
+	if util.TestError(&response.Diagnostics, err, "Failed to read minimum approvers merge check") {
+		return
 	}
 
-	allReviewerApproval := checksMap["com.atlassian.bitbucket.server.bitbucket-bundled-hooks:all-approvers-merge-check"]
-	state.AllReviewerApproval = types.BoolValue(allReviewerApproval.Enabled)
+	err = receiver.readMode(state, mergeCheckRequiredBuilds, &state.MinimumSuccessfulBuildMode)
+	if util.TestError(&response.Diagnostics, err, "Failed to read minimum build merge check") {
+		return
+	}
 
-	minimumApproval := checksMap["com.atlassian.bitbucket.server.bitbucket-bundled-hooks:all-approvers-merge-check"]
-	if minimumApproval.Enabled {
-		settings, err := receiver.client.RepositoryService().GetMergeCheckSetting(state.Project, "com.atlassian.bitbucket.server.bitbucket-bundled-hooks:requiredApproversMergeHook")
-		if util.TestError(&response.Diagnostics, err, "Failed get update minimum approvers merge check") {
-			return
-		}
+	// the merge checks report their effective state, which may be inherited from
+	// the project, so the values are read from the repository scoped hooks. A
+	// check enabled on the repository outside of Terraform shows up as a diff
+	allApprovers, err := receiver.readRepositoryHook(state, mergeCheckAllApprovers)
+	if util.TestError(&response.Diagnostics, err, "Failed to read all approvers merge check") {
+		return
+	}
 
-		atoi, err := strconv.Atoi(settings.RequiredCount)
-		state.MinimumApproval = types.Int64Value(int64(atoi))
-	} else {
-		state.MinimumApproval = types.Int64Null()
+	if allApprovers != nil || !state.AllReviewerApproval.IsNull() {
+		state.AllReviewerApproval = types.BoolValue(allApprovers != nil)
 	}
 
-	minimumBuild := checksMap["com.atlassian.bitbucket.server.bitbucket-build:requiredBuildsMergeCheck"]
-	if minimumBuild.Enabled {
-		settings, err := receiver.client.RepositoryService().GetMergeCheckSetting(state.Project, "com.atlassian.bitbucket.server.bitbucket-build:requiredBuildsMergeCheck")
-		if util.TestError(&response.Diagnostics, err, "Failed to get minimum approvers merge check") {
-			return
-		}
+	state.MinimumApproval, err = receiver.readRequiredCount(state, mergeCheckRequiredApproval)
+	if util.TestError(&response.Diagnostics, err, "Failed to read minimum approvers merge check") {
+		return
+	}
 
-		atoi, err := strconv.Atoi(settings.RequiredCount)
-		state.MinimumSuccessfulBuild = types.Int64Value(int64(atoi))
-	} else {
-		state.MinimumSuccessfulBuild = types.Int64Null()
+	state.MinimumSuccessfulBuild, err = receiver.readRequiredCount(state, mergeCheckRequiredBuilds)
+	if util.TestError(&response.Diagnostics, err, "Failed to read minimum build merge check") {
+		return
 	}
 
 	diags = response.State.Set(ctx, state)
 	response.Diagnostics.Append(diags...)
 }
 
+// applyChecks applies the planned checks. The mode of a check, when set, decides
+// its repository state; otherwise the check follows its value attribute and an
+// unset value disables the check on update.
+func (receiver *RepositoryMergeChecksResource) applyChecks(plan RepositoryMergeChecksModel, update bool) error {
+	err := receiver.applyCheck(plan, mergeCheckAllApprovers, plan.AllReviewerApprovalMode,
+		plan.AllReviewerApproval.ValueBool(), update,
+		func() error {
+			return receiver.client.RepositoryService().EnableMergeCheck(plan.Project, plan.Repo, mergeCheckAllApprovers)
+		})
+	if err != nil {
+		return err
+	}
+
+	err = receiver.applyCheck(plan, mergeCheckRequiredApproval, plan.MinimumApprovalMode,
+		!plan.MinimumApproval.IsNull(), update,
+		func() error {
+			return receiver.client.RepositoryService().ConfigureMergeCheck(plan.Project, plan.Repo, mergeCheckRequiredApproval, int(plan.MinimumApproval.ValueInt64()))
+		})
+	if err != nil {
+		return err
+	}
+
+	return receiver.applyCheck(plan, mergeCheckRequiredBuilds, plan.MinimumSuccessfulBuildMode,
+		!plan.MinimumSuccessfulBuild.IsNull(), update,
+		func() error {
+			return receiver.client.RepositoryService().ConfigureMergeCheck(plan.Project, plan.Repo, mergeCheckRequiredBuilds, int(plan.MinimumSuccessfulBuild.ValueInt64()))
+		})
+}
+
+func (receiver *RepositoryMergeChecksResource) applyCheck(plan RepositoryMergeChecksModel, key string, mode types.String, valueSet bool, update bool, enable func() error) error {
+	switch mode.ValueString() {
+	case mergeCheckModeInherit:
+		return receiver.extension.InheritHook(plan.Project, plan.Repo, key)
+	case mergeCheckModeDisabled:
+		return receiver.client.RepositoryService().DisableMergeCheck(plan.Project, plan.Repo, key)
+	case mergeCheckModeEnabled:
+		return enable()
+	}
+
+	if valueSet {
+		return enable()
+	} else if update {
+		return receiver.client.RepositoryService().DisableMergeCheck(plan.Project, plan.Repo, key)
+	}
+
+	return nil
+}
+
+// readMode refreshes the mode of a check when it is managed.
+func (receiver *RepositoryMergeChecksResource) readMode(state RepositoryMergeChecksModel, key string, mode *types.String) error {
+	if mode.IsNull() {
+		return nil
+	}
+
+	hook, err := receiver.extension.GetHook(state.Project, state.Repo, key)
+	if err != nil {
+		return err
+	}
+
+	*mode = readMergeCheckMode(hook, *mode)
+	return nil
+}
+
+// readRepositoryHook returns the hook of a check when the repository itself
+// enables it, or nil when the check is disabled or inherited from the project.
+func (receiver *RepositoryMergeChecksResource) readRepositoryHook(state RepositoryMergeChecksModel, key string) (*Hook, error) {
+	hook, err := receiver.extension.GetHook(state.Project, state.Repo, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if hook.Scope.Type == "PROJECT" || !hook.Enabled {
+		return nil, nil
+	}
+
+	return hook, nil
+}
+
+// readRequiredCount reads the requiredCount of a check the repository itself
+// enables, or returns null when the check is disabled or inherited.
+func (receiver *RepositoryMergeChecksResource) readRequiredCount(state RepositoryMergeChecksModel, key string) (types.Int64, error) {
+	hook, err := receiver.readRepositoryHook(state, key)
+	if err != nil || hook == nil {
+		return types.Int64Null(), err
+	}
+
+	settings, err := receiver.extension.GetHookSettings(state.Project, state.Repo, key)
+	if err != nil {
+		return types.Int64Null(), err
+	}
+
+	count, ok, err := readRequiredCount(settings)
+	if err != nil || !ok {
+		return types.Int64Null(), err
+	}
+
+	return types.Int64Value(count), nil
+}
+
 func (receiver *RepositoryMergeChecksResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
 	var (
 		diags       diag.Diagnostics
@@ -184,30 +290,8 @@ func (receiver *RepositoryMergeChecksResource) Update(ctx context.Context, reque
 		return
 	}
 
-	if plan.AllReviewerApproval.ValueBool() {
-		err = receiver.client.RepositoryService().EnableMergeCheck(plan.Project, plan.Repo, "com.atlassian.bitbucket.server.bitbucket-bundled-hooks:all-approvers-merge-check")
-	} else {
-		err = receiver.client.RepositoryService().DisableMergeCheck(plan.Project, plan.Repo, "com.atlassian.bitbucket.server.bitbucket-bundled-hooks:all-approvers-merge-check")
-	}
-	if util.TestError(&response.Diagnostics, err, "Failed to update all approvers merge check") {
-		return
-	}
-
-	if !plan.MinimumApproval.IsNull() {
-		err = receiver.client.RepositoryService().ConfigureMergeCheck(plan.Project, plan.Repo, "com.atlassian.bitbucket.server.bitbucket-bundled-hooks:requiredApproversMergeHook", int(plan.MinimumApproval.ValueInt64()))
-	} else {
-		err = receiver.client.RepositoryService().DisableMergeCheck(plan.Project, plan.Repo, "com.atlassian.bitbucket.server.bitbucket-bundled-hooks:requiredApproversMergeHook")
-	}
-	if util.TestError(&response.Diagnostics, err, "Failed to update minimum approvers merge check") {
-		return
-	}
-
-	if !plan.MinimumSuccessfulBuild.IsNull() {
-		err = receiver.client.RepositoryService().ConfigureMergeCheck(plan.Project, plan.Repo, "com.atlassian.bitbucket.server.bitbucket-build:requiredBuildsMergeCheck", int(plan.MinimumSuccessfulBuild.ValueInt64()))
-	} else {
-		err = receiver.client.RepositoryService().DisableMergeCheck(plan.Project, plan.Repo, "com.atlassian.bitbucket.server.bitbucket-build:requiredBuildsMergeCheck")
-	}
-	if util.TestError(&response.Diagnostics, err, "Failed to update minimum build merge check") {
+	err = receiver.applyChecks(plan, true)
+	if util.TestError(&response.Diagnostics, err, "Failed to update merge checks") {
 		return
 	}
 
@@ -228,19 +312,27 @@ func (receiver *RepositoryMergeChecksResource) Delete(ctx context.Context, reque
 		return
 	}
 
-	err = receiver.client.RepositoryService().DisableMergeCheck(state.Project, state.Repo, "com.atlassian.bitbucket.server.bitbucket-bundled-hooks:all-approvers-merge-check")
-	if util.TestError(&response.Diagnostics, err, "Failed to update all approvers merge check") {
-		return
+	// removing the repository settings restores the project settings of the
+	// checks, and checks that were never managed are left alone
+	checks := []struct {
+		key     string
+		managed bool
+		message string
+	}{
+		{mergeCheckAllApprovers, !state.AllReviewerApproval.IsNull() || !state.AllReviewerApprovalMode.IsNull(), "Failed to update all approvers merge check"},
+		{mergeCheckRequiredApproval, !state.MinimumApproval.IsNull() || !state.MinimumApprovalMode.IsNull(), "Failed to update minimum approvers merge check"},
+		{mergeCheckRequiredBuilds, !state.MinimumSuccessfulBuild.IsNull() || !state.MinimumSuccessfulBuildMode.IsNull(), "Failed to update minimum build merge check"},
 	}
 
-	err = receiver.client.RepositoryService().DisableMergeCheck(state.Project, state.Repo, "com.atlassian.bitbucket.server.bitbucket-bundled-hooks:all-approvers-merge-check")
-	if util.TestError(&response.Diagnostics, err, "Failed to update minimum approvers merge check") {
-		return
-	}
+	for _, check := range checks {
+		if !check.managed {
+			continue
+		}
 
-	err = receiver.client.RepositoryService().DisableMergeCheck(state.Project, state.Repo, "com.atlassian.bitbucket.server.bitbucket-bundled-hooks:all-approvers-merge-check")
-	if util.TestError(&response.Diagnostics, err, "Failed to update minimum build merge check") {
-		return
+		err = receiver.extension.InheritHook(state.Project, state.Repo, check.key)
+		if util.TestError(&response.Diagnostics, err, check.message) {
+			return
+		}
 	}
 
 	response.State.RemoveResource(ctx)
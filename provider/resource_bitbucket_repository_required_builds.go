@@ -0,0 +1,227 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yunarta/terraform-atlassian-api-client/bitbucket"
+	"github.com/yunarta/terraform-provider-commons/util"
+	"strconv"
+	"strings"
+)
+
+var (
+	_ resource.Resource                = &RepositoryRequiredBuildsResource{}
+	_ resource.ResourceWithConfigure   = &RepositoryRequiredBuildsResource{}
+	_ resource.ResourceWithImportState = &RepositoryRequiredBuildsResource{}
+	_ ConfigurableReceiver             = &RepositoryRequiredBuildsResource{}
+	_ ExtendedReceiver                 = &RepositoryRequiredBuildsResource{}
+)
+
+func NewRepositoryRequiredBuildsResource() resource.Resource {
+	return &RepositoryRequiredBuildsResource{}
+}
+
+type RepositoryRequiredBuildsResource struct {
+	config    BitbucketProviderConfig
+	client    *bitbucket.Client
+	extension *ClientExtension
+}
+
+func (receiver *RepositoryRequiredBuildsResource) getClient() *bitbucket.Client {
+	return receiver.client
+}
+
+func (receiver *RepositoryRequiredBuildsResource) setConfig(config BitbucketProviderConfig, client *bitbucket.Client) {
+	receiver.config = config
+	receiver.client = client
+}
+
+func (receiver *RepositoryRequiredBuildsResource) setExtension(extension *ClientExtension) {
+	receiver.extension = extension
+}
+
+func (receiver *RepositoryRequiredBuildsResource) Metadata(ctx context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_repository_required_builds"
+}
+
+func (receiver *RepositoryRequiredBuildsResource) Schema(ctx context.Context, request resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.Int64Attribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"project": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					util.ReplaceIfStringDiff(),
+				},
+			},
+			"repo": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					util.ReplaceIfStringDiff(),
+				},
+			},
+			"build_keys": schema.ListAttribute{
+				Required:    true,
+				ElementType: types.StringType,
+			},
+			"target": schema.SingleNestedAttribute{
+				Required:   true,
+				Attributes: refMatcherAttributes(),
+				Validators: []validator.Object{
+					refMatcherIdValidator{},
+				},
+			},
+			"exempt": schema.SingleNestedAttribute{
+				Optional:   true,
+				Attributes: refMatcherAttributes(),
+				Validators: []validator.Object{
+					refMatcherIdValidator{},
+				},
+				Description: "Refs that are exempt from the condition",
+			},
+		},
+	}
+}
+
+func (receiver *RepositoryRequiredBuildsResource) Configure(ctx context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
+	ConfigureResource(receiver, ctx, request, response)
+}
+
+func (receiver *RepositoryRequiredBuildsResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	var (
+		diags diag.Diagnostics
+
+		plan RepositoryRequiredBuildsModel
+	)
+
+	diags = request.Plan.Get(ctx, &plan)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	condition, err := receiver.extension.CreateRequiredBuildCondition(plan.Project.ValueString(), plan.Repo.ValueString(),
+		newRequiredBuildCondition(plan.BuildKeys, plan.Target, plan.Exempt),
+	)
+	if util.TestError(&response.Diagnostics, err, "Failed to create required builds") {
+		return
+	}
+
+	plan.Id = types.Int64Value(condition.Id)
+	plan.BuildKeys, plan.Target, plan.Exempt = readRequiredBuildCondition(condition, plan.BuildKeys, plan.Target, plan.Exempt)
+
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
+}
+
+func (receiver *RepositoryRequiredBuildsResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	var (
+		diags diag.Diagnostics
+
+		state RepositoryRequiredBuildsModel
+	)
+
+	diags = request.State.Get(ctx, &state)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	condition, err := receiver.extension.ReadRequiredBuildCondition(state.Project.ValueString(), state.Repo.ValueString(), state.Id.ValueInt64())
+	if util.TestError(&response.Diagnostics, err, "Failed to read required builds") {
+		return
+	}
+
+	if condition == nil {
+		response.State.RemoveResource(ctx)
+		return
+	}
+
+	state.BuildKeys, state.Target, state.Exempt = readRequiredBuildCondition(condition, state.BuildKeys, state.Target, state.Exempt)
+
+	diags = response.State.Set(ctx, state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (receiver *RepositoryRequiredBuildsResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	var (
+		diags diag.Diagnostics
+
+		plan, state RepositoryRequiredBuildsModel
+	)
+
+	if util.TestDiagnostics(&response.Diagnostics,
+		request.Plan.Get(ctx, &plan),
+		request.State.Get(ctx, &state)) {
+		return
+	}
+
+	condition, err := receiver.extension.UpdateRequiredBuildCondition(plan.Project.ValueString(), plan.Repo.ValueString(), state.Id.ValueInt64(),
+		newRequiredBuildCondition(plan.BuildKeys, plan.Target, plan.Exempt),
+	)
+	if util.TestError(&response.Diagnostics, err, "Failed to update required builds") {
+		return
+	}
+
+	plan.Id = state.Id
+	plan.BuildKeys, plan.Target, plan.Exempt = readRequiredBuildCondition(condition, plan.BuildKeys, plan.Target, plan.Exempt)
+
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
+}
+
+func (receiver *RepositoryRequiredBuildsResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	var (
+		diags diag.Diagnostics
+
+		state RepositoryRequiredBuildsModel
+	)
+
+	diags = request.State.Get(ctx, &state)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	err := receiver.extension.DeleteRequiredBuildCondition(state.Project.ValueString(), state.Repo.ValueString(), state.Id.ValueInt64())
+	if util.TestError(&response.Diagnostics, err, "Failed to delete required builds") {
+		return
+	}
+
+	response.State.RemoveResource(ctx)
+}
+
+func (receiver *RepositoryRequiredBuildsResource) ImportState(ctx context.Context, request resource.ImportStateRequest, response *resource.ImportStateResponse) {
+	slug := strings.Split(request.ID, "/")
+	if len(slug) != 3 {
+		response.Diagnostics.AddError("Invalid import id", fmt.Sprintf("Expected project/repo/id, got %s", request.ID))
+		return
+	}
+
+	id, err := strconv.ParseInt(slug[2], 10, 64)
+	if util.TestError(&response.Diagnostics, err, "Invalid import id") {
+		return
+	}
+
+	diags := response.State.Set(ctx, &RepositoryRequiredBuildsModel{
+		Id:      types.Int64Value(id),
+		Project: types.StringValue(slug[0]),
+		Repo:    types.StringValue(slug[1]),
+		Target: RefMatcherModel{
+			Type: types.StringNull(),
+			Id:   types.StringNull(),
+		},
+	})
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+}
@@ -0,0 +1,297 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yunarta/terraform-atlassian-api-client/bitbucket"
+	"github.com/yunarta/terraform-provider-commons/util"
+	"strconv"
+	"strings"
+)
+
+var (
+	_ resource.Resource                   = &ReviewerGroupResource{}
+	_ resource.ResourceWithConfigure      = &ReviewerGroupResource{}
+	_ resource.ResourceWithImportState    = &ReviewerGroupResource{}
+	_ resource.ResourceWithValidateConfig = &ReviewerGroupResource{}
+	_ ConfigurableReceiver                = &ReviewerGroupResource{}
+	_ ExtendedReceiver                    = &ReviewerGroupResource{}
+)
+
+func NewReviewerGroupResource() resource.Resource {
+	return &ReviewerGroupResource{}
+}
+
+type ReviewerGroupResource struct {
+	config    BitbucketProviderConfig
+	client    *bitbucket.Client
+	extension *ClientExtension
+}
+
+func (receiver *ReviewerGroupResource) getClient() *bitbucket.Client {
+	return receiver.client
+}
+
+func (receiver *ReviewerGroupResource) setConfig(config BitbucketProviderConfig, client *bitbucket.Client) {
+	receiver.config = config
+	receiver.client = client
+}
+
+func (receiver *ReviewerGroupResource) setExtension(extension *ClientExtension) {
+	receiver.extension = extension
+}
+
+func (receiver *ReviewerGroupResource) Metadata(ctx context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_reviewer_group"
+}
+
+func (receiver *ReviewerGroupResource) Schema(ctx context.Context, request resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.Int64Attribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
+			"scope": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					util.ReplaceIfStringDiff(),
+				},
+				Validators: []validator.String{
+					stringvalidator.OneOf(reviewerGroupScopeProject, reviewerGroupScopeRepository),
+				},
+			},
+			"project": schema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					util.ReplaceIfStringDiff(),
+				},
+			},
+			"repo": schema.StringAttribute{
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					util.ReplaceIfStringDiff(),
+				},
+				Description: "Repository slug, required when scope is repository",
+			},
+			"name": schema.StringAttribute{
+				Required: true,
+			},
+			"description": schema.StringAttribute{
+				Optional: true,
+			},
+			"users": schema.ListAttribute{
+				Required:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (receiver *ReviewerGroupResource) Configure(ctx context.Context, request resource.ConfigureRequest, response *resource.ConfigureResponse) {
+	ConfigureResource(receiver, ctx, request, response)
+}
+
+func (receiver *ReviewerGroupResource) ValidateConfig(ctx context.Context, request resource.ValidateConfigRequest, response *resource.ValidateConfigResponse) {
+	var config ReviewerGroupModel
+
+	diags := request.Config.Get(ctx, &config)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	if config.Scope.IsUnknown() || config.Repo.IsUnknown() {
+		return
+	}
+
+	if config.Scope.ValueString() == reviewerGroupScopeRepository && config.Repo.IsNull() {
+		response.Diagnostics.AddAttributeError(path.Root("repo"),
+			"Invalid Configuration",
+			"'repo' must be set when 'scope' is repository.",
+		)
+	} else if config.Scope.ValueString() == reviewerGroupScopeProject && !config.Repo.IsNull() {
+		response.Diagnostics.AddAttributeError(path.Root("repo"),
+			"Invalid Configuration",
+			"'repo' must not be set when 'scope' is project.",
+		)
+	}
+}
+
+// resolveUsers finds the configured users the same way default reviewers do,
+// and warns about the ones that do not exist.
+func (receiver *ReviewerGroupResource) resolveUsers(plan ReviewerGroupModel, diagnostics *diag.Diagnostics) (*ResolvedReviewers, error) {
+	project, repo := plan.getProjectAndRepo()
+	resolved, err := ResolveReviewers(receiver.client, receiver.extension, project, repo, plan.Users, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resolved.Unresolved) > 0 {
+		diagnostics.AddWarning("Unresolved users",
+			fmt.Sprintf("The following users could not be found: %s", strings.Join(resolved.Unresolved, ", ")),
+		)
+	}
+
+	return resolved, nil
+}
+
+func (receiver *ReviewerGroupResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	var (
+		diags diag.Diagnostics
+
+		plan ReviewerGroupModel
+	)
+
+	diags = request.Plan.Get(ctx, &plan)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	resolved, err := receiver.resolveUsers(plan, &response.Diagnostics)
+	if util.TestError(&response.Diagnostics, err, "Failed to resolve reviewer group users") {
+		return
+	}
+
+	project, repo := plan.getProjectAndRepo()
+	group, err := receiver.extension.CreateReviewerGroup(project, repo, ReviewerGroup{
+		Name:        plan.Name.ValueString(),
+		Description: plan.Description.ValueString(),
+		Users:       resolved.Users,
+	})
+	if util.TestError(&response.Diagnostics, err, "Failed to create reviewer group") {
+		return
+	}
+
+	diags = response.State.Set(ctx, NewReviewerGroupModel(plan, group, resolved))
+	response.Diagnostics.Append(diags...)
+}
+
+func (receiver *ReviewerGroupResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	var (
+		diags diag.Diagnostics
+
+		state ReviewerGroupModel
+	)
+
+	diags = request.State.Get(ctx, &state)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	project, repo := state.getProjectAndRepo()
+	group, err := receiver.extension.ReadReviewerGroup(project, repo, state.Id.ValueInt64())
+	if util.TestError(&response.Diagnostics, err, "Failed to read reviewer group") {
+		return
+	}
+
+	if group == nil {
+		response.State.RemoveResource(ctx)
+		return
+	}
+
+	resolved, err := ResolveReviewers(receiver.client, receiver.extension, project, repo, state.Users, nil)
+	if util.TestError(&response.Diagnostics, err, "Failed to resolve reviewer group users") {
+		return
+	}
+
+	diags = response.State.Set(ctx, NewReviewerGroupModel(state, group, resolved))
+	response.Diagnostics.Append(diags...)
+}
+
+func (receiver *ReviewerGroupResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	var (
+		diags diag.Diagnostics
+
+		plan, state ReviewerGroupModel
+	)
+
+	if util.TestDiagnostics(&response.Diagnostics,
+		request.Plan.Get(ctx, &plan),
+		request.State.Get(ctx, &state)) {
+		return
+	}
+
+	resolved, err := receiver.resolveUsers(plan, &response.Diagnostics)
+	if util.TestError(&response.Diagnostics, err, "Failed to resolve reviewer group users") {
+		return
+	}
+
+	project, repo := plan.getProjectAndRepo()
+	group, err := receiver.extension.UpdateReviewerGroup(project, repo, state.Id.ValueInt64(), ReviewerGroup{
+		Id:          state.Id.ValueInt64(),
+		Name:        plan.Name.ValueString(),
+		Description: plan.Description.ValueString(),
+		Users:       resolved.Users,
+	})
+	if util.TestError(&response.Diagnostics, err, "Failed to update reviewer group") {
+		return
+	}
+
+	diags = response.State.Set(ctx, NewReviewerGroupModel(plan, group, resolved))
+	response.Diagnostics.Append(diags...)
+}
+
+func (receiver *ReviewerGroupResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	var (
+		diags diag.Diagnostics
+
+		state ReviewerGroupModel
+	)
+
+	diags = request.State.Get(ctx, &state)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+
+	project, repo := state.getProjectAndRepo()
+	err := receiver.extension.DeleteReviewerGroup(project, repo, state.Id.ValueInt64())
+	if util.TestError(&response.Diagnostics, err, "Failed to delete reviewer group") {
+		return
+	}
+
+	response.State.RemoveResource(ctx)
+}
+
+// ImportState accepts project/id for project groups and project/repo/id for
+// repository groups.
+func (receiver *ReviewerGroupResource) ImportState(ctx context.Context, request resource.ImportStateRequest, response *resource.ImportStateResponse) {
+	slug := strings.Split(request.ID, "/")
+
+	model := ReviewerGroupModel{
+		Project: types.StringValue(slug[0]),
+		Repo:    types.StringNull(),
+		Scope:   types.StringValue(reviewerGroupScopeProject),
+	}
+
+	switch len(slug) {
+	case 2:
+	case 3:
+		model.Repo = types.StringValue(slug[1])
+		model.Scope = types.StringValue(reviewerGroupScopeRepository)
+	default:
+		response.Diagnostics.AddError("Invalid import id", fmt.Sprintf("Expected project/id or project/repo/id, got %s", request.ID))
+		return
+	}
+
+	id, err := strconv.ParseInt(slug[len(slug)-1], 10, 64)
+	if util.TestError(&response.Diagnostics, err, "Invalid import id") {
+		return
+	}
+	model.Id = types.Int64Value(id)
+
+	diags := response.State.Set(ctx, &model)
+	if util.TestDiagnostic(&response.Diagnostics, diags) {
+		return
+	}
+}
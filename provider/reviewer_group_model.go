@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/yunarta/terraform-provider-commons/util"
+)
+
+const (
+	reviewerGroupScopeProject    = "project"
+	reviewerGroupScopeRepository = "repository"
+)
+
+type ReviewerGroupModel struct {
+	Id          types.Int64  `tfsdk:"id"`
+	Scope       types.String `tfsdk:"scope"`
+	Project     types.String `tfsdk:"project"`
+	Repo        types.String `tfsdk:"repo"`
+	Name        types.String `tfsdk:"name"`
+	Description types.String `tfsdk:"description"`
+	Users       []string     `tfsdk:"users"`
+}
+
+// getProjectAndRepo returns the project key, and the repository slug only when
+// the group is scoped to a repository.
+func (m ReviewerGroupModel) getProjectAndRepo() (string, string) {
+	if m.Scope.ValueString() == reviewerGroupScopeRepository {
+		return m.Project.ValueString(), m.Repo.ValueString()
+	}
+
+	return m.Project.ValueString(), ""
+}
+
+func NewReviewerGroupModel(plan ReviewerGroupModel, group *ReviewerGroup, resolved *ResolvedReviewers) *ReviewerGroupModel {
+	// the server drops an empty description, which is kept as configured
+	description := util.NullString(group.Description)
+	if group.Description == "" && plan.Description.ValueString() == "" && !plan.Description.IsUnknown() {
+		description = plan.Description
+	}
+
+	return &ReviewerGroupModel{
+		Id:          types.Int64Value(group.Id),
+		Scope:       plan.Scope,
+		Project:     plan.Project,
+		Repo:        plan.Repo,
+		Name:        types.StringValue(group.Name),
+		Description: description,
+		Users:       ReadReviewers(group.Users, plan.Users, resolved),
+	}
+}